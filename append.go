@@ -0,0 +1,106 @@
+package sbor
+
+import (
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
+	"time"
+)
+
+// The Append functions encode a single value and append it to the end of a byte slice,
+// returning the extended slice. They produce exactly the same bytes as Marshal, and they
+// are the building blocks of the code written by the sborgen command.
+
+// sliceWriter is an io.Writer that appends all the written bytes to itself.
+type sliceWriter []byte
+
+func (s *sliceWriter) Write(p []byte) (int, error) {
+	*s = append(*s, p...)
+	return len(p), nil
+}
+
+// appendType appends the encoding of t to b.
+func appendType(b []byte, t utils.MessagePackTypeEncoder) ([]byte, error) {
+	w := sliceWriter(b)
+	_, err := t.WriteTo(&w)
+	return w, err
+}
+
+// AppendNil appends the MessagePack nil to b.
+func AppendNil(b []byte) []byte {
+	return append(b, types.NilCode)
+}
+
+// AppendBool appends the MessagePack encoding of v to b.
+func AppendBool(b []byte, v bool) []byte {
+	b, _ = appendType(b, types.Boolean(v))
+	return b
+}
+
+// AppendInt appends the MessagePack encoding of i to b, using the smallest int format.
+func AppendInt(b []byte, i int64) []byte {
+	b, _ = appendType(b, types.Int(i))
+	return b
+}
+
+// AppendUint appends the MessagePack encoding of u to b, using the smallest uint format.
+func AppendUint(b []byte, u uint64) []byte {
+	b, _ = appendType(b, types.Uint(u))
+	return b
+}
+
+// AppendFloat appends the MessagePack encoding of f to b.
+// A float32 is used when f can be represented without loss of precision.
+func AppendFloat(b []byte, f float64) []byte {
+	b, _ = appendType(b, types.Float(f))
+	return b
+}
+
+// AppendString appends the MessagePack encoding of s to b.
+func AppendString(b []byte, s string) ([]byte, error) {
+	return appendType(b, types.String(s))
+}
+
+// AppendBinary appends the MessagePack binary encoding of data to b.
+func AppendBinary(b []byte, data []byte) ([]byte, error) {
+	return appendType(b, types.Binary(data))
+}
+
+// AppendTime appends t to b as a MessagePack timestamp (external type -1).
+func AppendTime(b []byte, t time.Time) []byte {
+	b, _ = appendType(b, encode.NewTimestamp(t))
+	return b
+}
+
+// AppendMapHeader appends to b the header of a MessagePack map with length entries.
+// The caller must append the length key-value pairs after it.
+func AppendMapHeader(b []byte, length int) ([]byte, error) {
+	header, err := types.MapHeader(length)
+	return append(b, header...), err
+}
+
+// AppendArrayHeader appends to b the header of a MessagePack array with length elements.
+// The caller must append the length elements after it.
+func AppendArrayHeader(b []byte, length int) ([]byte, error) {
+	header, err := types.ArrayHeader(length)
+	return append(b, header...), err
+}
+
+// AppendValue appends the MessagePack encoding of v to b, using reflection.
+// See the documentation for Marshal for details about the conversion.
+func AppendValue(b []byte, v interface{}) ([]byte, error) {
+	state := encode.NewEncoderState()
+	return appendType(b, state.TypeWrapper(reflect.ValueOf(v)))
+}
+
+// AppendCustomKey appends to b the MessagePack key associated with name
+// in a map tagged with the "setcustomkeys" option.
+// It returns an error if name isn't in keys, like the "customkey" option does.
+func AppendCustomKey[V any](b []byte, keys map[string]V, name string) ([]byte, error) {
+	key, ok := keys[name]
+	if !ok {
+		return b, utils.InvalidTypeError{Type: "invalid key " + name + " using customkey option"}
+	}
+	return AppendValue(b, key)
+}
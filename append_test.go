@@ -0,0 +1,76 @@
+package sbor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestAppend_Equal_Marshal(t *testing.T) {
+	appendString := func(b []byte, s string) []byte {
+		b, _ = AppendString(b, s)
+		return b
+	}
+	appendBinary := func(b []byte, data []byte) []byte {
+		b, _ = AppendBinary(b, data)
+		return b
+	}
+	appendValue := func(b []byte, v interface{}) []byte {
+		b, _ = AppendValue(b, v)
+		return b
+	}
+
+	data := []struct {
+		result []byte
+		input  interface{}
+		name   string
+	}{
+		{result: AppendNil(nil), input: nil, name: "nil"},
+		{result: AppendBool(nil, true), input: true, name: "boolean"},
+		{result: AppendInt(nil, -40000), input: -40000, name: "int"},
+		{result: AppendUint(nil, 32000), input: uint(32000), name: "uint"},
+		{result: AppendFloat(nil, 9.5), input: 9.5, name: "float"},
+		{result: appendString(nil, "hello world"), input: "hello world", name: "string"},
+		{result: appendBinary(nil, []byte{0x01, 0x02}), input: []byte{0x01, 0x02}, name: "binary"},
+		{result: AppendTime(nil, time.Unix(1646580000, 0)), input: time.Unix(1646580000, 0), name: "time"},
+		{result: appendValue(nil, []string{"foo", "bar"}), input: []string{"foo", "bar"}, name: "value"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			expected, err := Marshal(test.input)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if !bytes.Equal(test.result, expected) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", test.result, expected)
+			}
+		})
+	}
+}
+
+func TestAppendHeaders(t *testing.T) {
+	b, err := AppendMapHeader([]byte{0xC0}, 2)
+	if err != nil || !bytes.Equal(b, []byte{0xC0, 0x82}) {
+		t.Errorf("Invalid map header %v. Error: %v", b, err)
+	}
+
+	b, err = AppendArrayHeader(nil, 16)
+	if err != nil || !bytes.Equal(b, []byte{0xDC, 0x00, 0x10}) {
+		t.Errorf("Invalid array header %v. Error: %v", b, err)
+	}
+}
+
+func TestAppendCustomKey(t *testing.T) {
+	keys := map[string]int8{"b": -8}
+
+	b, err := AppendCustomKey(nil, keys, "b")
+	if err != nil || !bytes.Equal(b, []byte{0xF8}) {
+		t.Errorf("Invalid custom key %v. Error: %v", b, err)
+	}
+
+	if _, err = AppendCustomKey(nil, keys, "c"); err == nil {
+		t.Error("Error was expected.")
+	}
+}
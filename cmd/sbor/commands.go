@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/ErikPelli/sbor"
+	"io"
+)
+
+func encodeCommand(args []string, in io.Reader, out io.Writer) error {
+	flags, stream := newFlagSet("encode")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := sbor.FromJSON(&b, in); err != nil {
+		return err
+	}
+	if !*stream {
+		if err := sbor.Valid(b.Bytes()); err != nil {
+			return singleObject(err)
+		}
+	}
+
+	_, err := b.WriteTo(out)
+	return err
+}
+
+func decodeCommand(args []string, in io.Reader, out io.Writer) error {
+	flags, stream := newFlagSet("decode")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	if !*stream {
+		if err = sbor.Valid(data); err != nil {
+			return singleObject(err)
+		}
+	}
+	return sbor.ToJSON(out, data)
+}
+
+func dumpCommand(args []string, in io.Reader, out io.Writer) error {
+	flags, stream := newFlagSet("dump")
+	compact := flags.Bool("compact", false, "write the diagnostic notation, one object per line")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	// Dump all the objects anyway, to help finding the problem
+	if err = sbor.Dump(out, data, sbor.DumpOptions{Compact: *compact}); err != nil {
+		return err
+	}
+	if !*stream {
+		return singleObject(sbor.Valid(data))
+	}
+	return nil
+}
+
+func validateCommand(args []string, in io.Reader, out io.Writer) error {
+	flags, stream := newFlagSet("validate")
+	strictUTF8 := flags.Bool("strict-utf8", false, "check that all the strings are valid UTF-8")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	opts := sbor.ValidOptions{StrictUTF8: *strictUTF8}
+	if !*stream {
+		if err = opts.Valid(data); err != nil {
+			return singleObject(err)
+		}
+		_, err = fmt.Fprintln(out, "valid")
+		return err
+	}
+
+	objects, err := opts.ValidStream(data)
+	if err != nil {
+		return fmt.Errorf("%w (after %d valid objects)", err, objects)
+	}
+	_, err = fmt.Fprintf(out, "valid: %d objects\n", objects)
+	return err
+}
+
+// singleObject adds a hint about --stream to the error returned when the input
+// contains more than one object.
+func singleObject(err error) error {
+	var syntax sbor.SyntaxError
+	if errors.As(err, &syntax) && syntax.Desc == "unexpected data after the object" {
+		return fmt.Errorf("%w, use --stream for concatenated objects", err)
+	}
+	return err
+}
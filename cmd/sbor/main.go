@@ -0,0 +1,85 @@
+// Command sbor converts between JSON and MessagePack, and inspects MessagePack data,
+// using the same rules as the sbor package.
+//
+// Usage:
+//
+//	sbor encode [--stream]
+//	sbor decode [--stream]
+//	sbor dump [--stream] [--compact]
+//	sbor validate [--stream] [--strict-utf8]
+//
+// All the commands read from the standard input and write to the standard output.
+//
+// encode converts JSON to MessagePack, and decode converts MessagePack to JSON, using
+// the lossless convention of sbor.ToJSON and sbor.FromJSON: binary payloads are written
+// as {"$bin": "base64"} objects, external types as {"$ext": [type, "base64"]} objects,
+// and so on. The keys of the objects keep their order.
+//
+// dump prints an annotated view of MessagePack data, or its diagnostic notation with
+// --compact. validate checks that the input is well-formed MessagePack data, and
+// that the strings are valid UTF-8 with --strict-utf8.
+//
+// Without --stream the input must contain exactly one JSON value or MessagePack object.
+// With --stream it can contain any number of concatenated values. decode always writes
+// one JSON value per line.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// command runs a subcommand with the given arguments.
+type command func(args []string, in io.Reader, out io.Writer) error
+
+var commands = map[string]command{
+	"encode":   encodeCommand,
+	"decode":   decodeCommand,
+	"dump":     dumpCommand,
+	"validate": validateCommand,
+}
+
+func usage() {
+	_, _ = fmt.Fprintf(os.Stderr, "usage: sbor encode|decode|dump|validate [--stream] [flags] < input\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd(os.Args[2:], os.Stdin, os.Stdout); err == flag.ErrHelp {
+		os.Exit(2)
+	} else if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "sbor "+os.Args[1]+":", err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet returns the flags of a subcommand, with the --stream flag already defined.
+func newFlagSet(name string) (*flag.FlagSet, *bool) {
+	flags := flag.NewFlagSet("sbor "+name, flag.ContinueOnError)
+	stream := flags.Bool("stream", false, "the input contains any number of concatenated objects")
+	return flags, stream
+}
+
+// parseFlags parses the arguments of a subcommand, that doesn't accept positional arguments.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return flag.ErrHelp
+	}
+	return nil
+}
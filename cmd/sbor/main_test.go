@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"github.com/ErikPelli/sbor"
+	"strings"
+	"testing"
+)
+
+// diag returns the MessagePack encoding of the diagnostic notation s.
+func diag(t *testing.T, s string) string {
+	b, err := sbor.ParseDiag(s)
+	if err != nil {
+		t.Fatalf("ParseDiag Error: %v", err)
+	}
+	return string(b)
+}
+
+func TestCommands(t *testing.T) {
+	data := []struct {
+		command  string
+		args     []string
+		input    string
+		expected string
+		name     string
+	}{
+		{command: "encode", input: `{"b": [1, -2, 1.5, "x"], "a": 18446744073709551615, "n": null}`, expected: diag(t, `{"b": [1, -2, 1.5, "x"], "a": 18446744073709551615, "n": nil}`), name: "Encode"},
+		{command: "encode", args: []string{"--stream"}, input: "true\n[]\n0.1", expected: diag(t, "true [] 0.1"), name: "Encode stream"},
+		{command: "decode", input: diag(t, `{"b": h'ff', "t": ext(5, h'01020304'), "f": 2.5f64, "s": "<a>"}`), expected: `{"b":{"$bin":"/w=="},"t":{"$ext":[5,"AQIDBA=="]},"f":{"$float64":2.5},"s":"<a>"}` + "\n", name: "Decode"},
+		{command: "decode", args: []string{"--stream"}, input: diag(t, `"a" [1, {2: NaN}] -1`), expected: "\"a\"\n[1,{\"$map\":[[2,{\"$float64\":\"NaN\"}]]}]\n-1\n", name: "Decode stream"},
+		{command: "encode", input: `{"$bin": "/w==", "$time": "2022-03-06T15:20:00Z"}`, expected: diag(t, `{"$bin": "/w==", "$time": "2022-03-06T15:20:00Z"}`), name: "Encode tag keys"},
+		{command: "encode", input: `[{"$bin": "/w=="}, {"$uint16": 1}]`, expected: diag(t, `[h'ff', 1_u16]`), name: "Encode tags"},
+		{command: "dump", args: []string{"--compact", "--stream"}, input: diag(t, "1_u16 [true]"), expected: "1_u16\n[true]\n", name: "Dump stream"},
+		{command: "dump", input: diag(t, "[nil]"), expected: "0000  fixarray len=1\n0001    nil\n", name: "Dump"},
+		{command: "validate", input: diag(t, `{"a": 1}`), expected: "valid\n", name: "Validate"},
+		{command: "validate", args: []string{"--stream"}, input: diag(t, "1 2 3"), expected: "valid: 3 objects\n", name: "Validate stream"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := commands[test.command](test.args, strings.NewReader(test.input), &out); err != nil {
+				t.Fatalf("Command Error: %v", err)
+			}
+
+			if out.String() != test.expected {
+				t.Errorf("Invalid result. Command returned %q. Expected %q.", out.String(), test.expected)
+			}
+		})
+	}
+}
+
+func TestCommands_Error(t *testing.T) {
+	data := []struct {
+		command string
+		args    []string
+		input   string
+		name    string
+	}{
+		{command: "encode", input: "1 2", name: "Encode more values"},
+		{command: "encode", input: "", name: "Encode empty"},
+		{command: "encode", input: `{"a": }`, name: "Encode invalid"},
+		{command: "decode", input: diag(t, "1 2"), name: "Decode more values"},
+		{command: "decode", args: []string{"--stream"}, input: "\x92\x01", name: "Decode truncated"},
+		{command: "dump", input: diag(t, "1 2"), name: "Dump more values"},
+		{command: "validate", args: []string{"--strict-utf8"}, input: "\xa1\xff", name: "Validate UTF-8"},
+		{command: "validate", args: []string{"extra"}, input: "\xc0", name: "Positional argument"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := commands[test.command](test.args, strings.NewReader(test.input), &out); err == nil {
+				t.Errorf("Error was expected. Command returned %q.", out.String())
+			}
+		})
+	}
+}
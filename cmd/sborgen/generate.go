@@ -0,0 +1,520 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ErikPelli/sbor"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"go/format"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// structField is a struct field that takes part in the encoding.
+type structField struct {
+	name          string // Go field name
+	key           string // MessagePack key, or custom key name
+	typ           types.Type
+	omitEmpty     bool
+	omitZero      bool
+	quoted        bool // Booleans and numbers written as strings
+	structArray   bool
+	setCustomKeys bool
+	customKey     bool
+}
+
+// generator writes the source code of the encoding methods.
+type generator struct {
+	buf      bytes.Buffer
+	tagNames []string // Keys of the struct field's tag, in order of precedence
+	imports  map[string]struct{}
+	targets  map[*types.Named]struct{}
+	vars     int // Counter used to give a unique name to the loop variables
+}
+
+// generate returns the formatted source code of the encoding methods of all the
+// annotated types in pkg, reading the struct tags with the given keys.
+func generate(pkg *sourcePackage, tagNames []string) ([]byte, error) {
+	g := &generator{
+		tagNames: tagNames,
+		imports:  map[string]struct{}{"github.com/ErikPelli/sbor": {}},
+		targets:  make(map[*types.Named]struct{}, len(pkg.targets)),
+	}
+	for _, named := range pkg.targets {
+		g.targets[named] = struct{}{}
+	}
+
+	for _, named := range pkg.targets {
+		if err := g.writeStruct(named); err != nil {
+			return nil, err
+		}
+	}
+
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+
+	var file bytes.Buffer
+	file.WriteString(generatedHeader + "\n\n")
+	file.WriteString("package " + pkg.types.Name() + "\n\n")
+	file.WriteString("import (\n")
+	for _, path := range imports {
+		file.WriteString(strconv.Quote(path) + "\n")
+	}
+	file.WriteString(")\n")
+	file.Write(g.buf.Bytes())
+
+	return format.Source(file.Bytes())
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(&g.buf, format, args...)
+}
+
+// parseFields returns the fields of s that take part in the encoding,
+// with the same rules used by the reflection based encoder.
+// The tag of each field is the first one of tagNames that is present.
+func parseFields(name string, s *types.Struct, tagNames []string) ([]structField, error) {
+	fields := make([]structField, 0, s.NumFields())
+	usedKeys := make(map[string]struct{}, s.NumFields())
+	usedCustomKeys := make(map[string]struct{})
+	var customKeys bool
+
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		if !field.Exported() {
+			continue
+		}
+
+		tagValue, _ := utils.LookupTag(reflect.StructTag(s.Tag(i)), tagNames)
+		tagName, tagOptions := utils.ParseTag(tagValue)
+
+		if tagName == "-" && len(tagValue) == 1 {
+			continue
+		}
+
+		current := structField{
+			name:          field.Name(),
+			key:           field.Name(),
+			typ:           field.Type(),
+			omitEmpty:     tagOptions.Contains("omitempty"),
+			omitZero:      tagOptions.Contains("omitzero"),
+			structArray:   tagOptions.Contains("structarray"),
+			setCustomKeys: tagOptions.Contains("setcustomkeys"),
+			customKey:     tagOptions.Contains("customkey"),
+		}
+		if tagName != "" {
+			current.key = tagName
+		}
+		current.quoted = tagOptions.Contains("string") && quotable(current.typ)
+
+		// The generated code always uses the default formats for the numbers and the times
+		_, floatOption := tagOptions.Value("float")
+		_, intOption := tagOptions.Value("int")
+		_, timeOption := tagOptions.Value("time")
+		if floatOption || intOption || timeOption || tagOptions.Contains("signed") {
+			return nil, fmt.Errorf("%s.%s: the float, int, signed and time options are not supported", name, current.name)
+		}
+
+		switch {
+		case current.setCustomKeys:
+			if customKeys {
+				return nil, fmt.Errorf("%s: multiple setcustomkeys fields are not supported", name)
+			}
+			m, ok := field.Type().Underlying().(*types.Map)
+			if !ok || !types.Identical(m.Key().Underlying(), types.Typ[types.String]) {
+				return nil, fmt.Errorf("%s.%s: invalid custom keys type", name, current.name)
+			}
+			customKeys = true
+
+		case current.customKey:
+			if !customKeys {
+				return nil, fmt.Errorf("%s.%s: customkey without a previous setcustomkeys field", name, current.name)
+			}
+			if _, already := usedCustomKeys[current.key]; already {
+				return nil, fmt.Errorf("%s: duplicated custom key %s", name, current.key)
+			}
+			usedCustomKeys[current.key] = struct{}{}
+
+		default:
+			if _, already := usedKeys[current.key]; already {
+				return nil, fmt.Errorf("%s: %w", name, utils.DuplicatedKeyError{Key: current.key})
+			}
+			usedKeys[current.key] = struct{}{}
+		}
+
+		fields = append(fields, current)
+	}
+
+	return fields, nil
+}
+
+// writeStruct writes the MarshalMsgpack and AppendMsgpack methods of named.
+func (g *generator) writeStruct(named *types.Named) error {
+	typeName := named.Obj().Name()
+	g.vars = 0
+	fields, err := parseFields(typeName, named.Underlying().(*types.Struct), g.tagNames)
+	if err != nil {
+		return err
+	}
+
+	g.printf("\n// MarshalMsgpack returns the MessagePack encoding of x.\n")
+	g.printf("func (x %s) MarshalMsgpack() ([]byte, error) {\n", typeName)
+	g.printf("return x.AppendMsgpack(nil)\n}\n")
+
+	g.printf("\n// AppendMsgpack appends the MessagePack encoding of x to b.\n")
+	g.printf("func (x %s) AppendMsgpack(b []byte) ([]byte, error) {\n", typeName)
+	g.printf("var err error\n")
+
+	var customKeysField string
+
+	// Fields omitted at runtime change the length of the header
+	var staticCount int
+	var dynamicCount []string
+	var staticArray bool
+	var dynamicArray []string
+
+	for _, f := range fields {
+		if f.setCustomKeys {
+			// An omitted custom keys map is nil, so it can be used directly
+			customKeysField = "x." + f.name
+			continue
+		}
+
+		if f.optional() {
+			g.printf("empty%s := %s\n", f.name, g.omitCheck(f))
+			dynamicCount = append(dynamicCount, f.name)
+		} else {
+			staticCount++
+		}
+
+		if f.structArray {
+			if f.optional() {
+				dynamicArray = append(dynamicArray, f.name)
+			} else {
+				staticArray = true
+			}
+		}
+	}
+
+	count := strconv.Itoa(staticCount)
+	if len(dynamicCount) > 0 {
+		g.printf("n := %d\n", staticCount)
+		for _, name := range dynamicCount {
+			g.printf("if !empty%s {\nn++\n}\n", name)
+		}
+		count = "n"
+	}
+
+	// Encoding as an array can depend on omitted fields
+	asArray := "false"
+	if staticArray {
+		asArray = "true"
+	} else if len(dynamicArray) > 0 {
+		asArray = "asArray"
+		g.printf("asArray := false\n")
+		for _, name := range dynamicArray {
+			g.printf("if !empty%s {\nasArray = true\n}\n", name)
+		}
+	}
+
+	switch asArray {
+	case "true":
+		g.printf("if b, err = sbor.AppendArrayHeader(b, %s); err != nil {\nreturn b, err\n}\n", count)
+	case "false":
+		g.printf("if b, err = sbor.AppendMapHeader(b, %s); err != nil {\nreturn b, err\n}\n", count)
+	default:
+		g.printf("if asArray {\nb, err = sbor.AppendArrayHeader(b, %s)\n} else {\n", count)
+		g.printf("b, err = sbor.AppendMapHeader(b, %s)\n}\n", count)
+		g.printf("if err != nil {\nreturn b, err\n}\n")
+	}
+
+	for _, f := range fields {
+		if f.setCustomKeys {
+			continue
+		}
+
+		g.printf("\n// %s\n", f.name)
+		if f.optional() {
+			g.printf("if !empty%s {\n", f.name)
+		}
+
+		// Key
+		switch {
+		case f.customKey && asArray == "true":
+			// The custom key must exist even if it isn't written
+			g.printf("if _, err = sbor.AppendCustomKey(nil, %s, %q); err != nil {\nreturn b, err\n}\n", customKeysField, f.key)
+		case f.customKey && asArray == "false":
+			g.printf("if b, err = sbor.AppendCustomKey(b, %s, %q); err != nil {\nreturn b, err\n}\n", customKeysField, f.key)
+		case f.customKey:
+			g.printf("if asArray {\n_, err = sbor.AppendCustomKey(nil, %s, %q)\n} else {\n", customKeysField, f.key)
+			g.printf("b, err = sbor.AppendCustomKey(b, %s, %q)\n}\n", customKeysField, f.key)
+			g.printf("if err != nil {\nreturn b, err\n}\n")
+		default:
+			key, err := sbor.AppendString(nil, f.key)
+			if err != nil {
+				return err
+			}
+			switch asArray {
+			case "true":
+			case "false":
+				g.printf("b = append(b, %s...)\n", strconv.Quote(string(key)))
+			default:
+				g.printf("if !asArray {\nb = append(b, %s...)\n}\n", strconv.Quote(string(key)))
+			}
+		}
+
+		// Value
+		if f.quoted {
+			g.writeQuoted("x."+f.name, f.typ)
+		} else {
+			g.writeValue("x."+f.name, f.typ)
+		}
+
+		if f.optional() {
+			g.printf("}\n")
+		}
+	}
+
+	g.printf("\nreturn b, nil\n}\n")
+	return nil
+}
+
+// optional reports whether the field can be omitted at runtime.
+func (f structField) optional() bool {
+	return f.omitEmpty || f.omitZero
+}
+
+// zeroer is the interface of the types that define their own zero value.
+var zeroer = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "IsZero", types.NewSignatureType(nil, nil, nil, nil,
+		types.NewTuple(types.NewVar(token.NoPos, nil, "", types.Typ[types.Bool])), false)),
+}, nil).Complete()
+
+// omitCheck returns an expression that reports whether the field f must be omitted,
+// like the omitempty and omitzero options of the reflection based encoder.
+func (g *generator) omitCheck(f structField) string {
+	expr := "x." + f.name
+	var checks []string
+
+	if f.omitEmpty {
+		switch u := f.typ.Underlying().(type) {
+		case *types.Slice, *types.Map:
+			checks = append(checks, "len("+expr+") == 0")
+		case *types.Array:
+			checks = append(checks, strconv.FormatBool(u.Len() == 0))
+		case *types.Basic:
+			if u.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsString) != 0 {
+				checks = append(checks, g.zeroCheck(expr, f.typ))
+			} else {
+				checks = append(checks, "false")
+			}
+		case *types.Pointer, *types.Interface:
+			checks = append(checks, g.zeroCheck(expr, f.typ))
+		default:
+			// A struct is never empty
+			checks = append(checks, "false")
+		}
+	}
+
+	if f.omitZero {
+		switch f.typ.Underlying().(type) {
+		case *types.Pointer, *types.Interface:
+			if types.Implements(f.typ, zeroer) {
+				// The method is never called on nil
+				checks = append(checks, expr+" == nil || "+expr+".IsZero()")
+			} else {
+				checks = append(checks, g.zeroCheck(expr, f.typ))
+			}
+		default:
+			if types.Implements(f.typ, zeroer) {
+				checks = append(checks, expr+".IsZero()")
+			} else {
+				checks = append(checks, g.zeroCheck(expr, f.typ))
+			}
+		}
+	}
+
+	return strings.Join(checks, " || ")
+}
+
+// zeroCheck returns an expression that reports whether expr contains
+// the zero value of its type, like reflect.Value.IsZero does.
+func (g *generator) zeroCheck(expr string, t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "!" + expr
+		case u.Info()&(types.IsInteger|types.IsFloat) != 0:
+			return expr + " == 0"
+		case u.Info()&types.IsString != 0:
+			return expr + ` == ""`
+		case u.Kind() == types.UnsafePointer:
+			return expr + " == nil"
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return expr + " == nil"
+	}
+
+	g.imports["reflect"] = struct{}{}
+	return "reflect.ValueOf(" + expr + ").IsZero()"
+}
+
+// newVar returns a new unique variable name with the given prefix.
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+	return prefix + strconv.Itoa(g.vars)
+}
+
+// writeValue writes the code that appends to b the encoding of expr, of type t.
+func (g *generator) writeValue(expr string, t types.Type) {
+	if named, ok := t.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			g.printf("b = sbor.AppendTime(b, %s)\n", expr)
+			return
+		}
+		if _, ok := g.targets[named]; ok {
+			g.printf("if b, err = %s.AppendMsgpack(b); err != nil {\nreturn b, err\n}\n", expr)
+			return
+		}
+		if obj.Pkg() != nil && obj.Pkg().Path() == "github.com/ErikPelli/sbor" {
+			// Types with their own encoding, like RawMessage and Value
+			g.writeFallback(expr)
+			return
+		}
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			g.printf("b = sbor.AppendBool(b, %s)\n", convert(expr, t, types.Bool))
+			return
+		case u.Info()&types.IsInteger != 0 && u.Info()&types.IsUnsigned == 0:
+			g.printf("b = sbor.AppendInt(b, %s)\n", convert(expr, t, types.Int64))
+			return
+		case u.Info()&types.IsUnsigned != 0 && u.Kind() != types.Uintptr:
+			g.printf("b = sbor.AppendUint(b, %s)\n", convert(expr, t, types.Uint64))
+			return
+		case u.Info()&types.IsFloat != 0:
+			g.printf("b = sbor.AppendFloat(b, %s)\n", convert(expr, t, types.Float64))
+			return
+		case u.Info()&types.IsString != 0:
+			g.printf("if b, err = sbor.AppendString(b, %s); err != nil {\nreturn b, err\n}\n", convert(expr, t, types.String))
+			return
+		}
+
+	case *types.Pointer:
+		g.printf("if %s == nil {\nb = sbor.AppendNil(b)\n} else {\n", expr)
+		g.writeValue("(*"+expr+")", u.Elem())
+		g.printf("}\n")
+		return
+
+	case *types.Slice:
+		// Only the []byte type is encoded as binary, named types are arrays
+		if types.Identical(t, types.NewSlice(types.Typ[types.Uint8])) {
+			g.printf("if b, err = sbor.AppendBinary(b, %s); err != nil {\nreturn b, err\n}\n", expr)
+			return
+		}
+		g.writeArray(expr, u.Elem())
+		return
+
+	case *types.Array:
+		g.writeArray(expr, u.Elem())
+		return
+
+	case *types.Map:
+		// The maps with composite keys are sorted by sbor.Marshal
+		switch u.Key().Underlying().(type) {
+		case *types.Array, *types.Struct, *types.Pointer:
+			g.writeFallback(expr)
+			return
+		}
+
+		key := g.newVar("k")
+		value := g.newVar("v")
+		g.printf("if b, err = sbor.AppendMapHeader(b, len(%s)); err != nil {\nreturn b, err\n}\n", expr)
+		g.printf("for %s, %s := range %s {\n", key, value, expr)
+		g.writeValue(key, u.Key())
+		g.writeValue(value, u.Elem())
+		g.printf("}\n")
+		return
+	}
+
+	// Interfaces, channels, external structs and unsupported types use reflection
+	g.writeFallback(expr)
+}
+
+// quotable reports whether the string option applies to a field of type t, like utils.Quotable.
+func quotable(t types.Type) bool {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		t = p.Elem()
+	}
+
+	u, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return false
+	}
+	return u.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat) != 0 && u.Kind() != types.Uintptr
+}
+
+// writeQuoted writes the code that appends to b the decimal form of expr as a string,
+// for a field with the string option.
+func (g *generator) writeQuoted(expr string, t types.Type) {
+	if p, ok := t.Underlying().(*types.Pointer); ok {
+		g.printf("if %s == nil {\nb = sbor.AppendNil(b)\n} else {\n", expr)
+		g.writeQuoted("(*"+expr+")", p.Elem())
+		g.printf("}\n")
+		return
+	}
+
+	g.imports["strconv"] = struct{}{}
+	u := t.Underlying().(*types.Basic)
+
+	var s string
+	switch {
+	case u.Info()&types.IsBoolean != 0:
+		s = "strconv.FormatBool(" + convert(expr, t, types.Bool) + ")"
+	case u.Info()&types.IsUnsigned != 0:
+		s = "strconv.FormatUint(" + convert(expr, t, types.Uint64) + ", 10)"
+	case u.Info()&types.IsInteger != 0:
+		s = "strconv.FormatInt(" + convert(expr, t, types.Int64) + ", 10)"
+	case u.Kind() == types.Float32:
+		s = "strconv.FormatFloat(" + convert(expr, t, types.Float64) + ", 'g', -1, 32)"
+	default:
+		s = "strconv.FormatFloat(" + convert(expr, t, types.Float64) + ", 'g', -1, 64)"
+	}
+	g.printf("if b, err = sbor.AppendString(b, %s); err != nil {\nreturn b, err\n}\n", s)
+}
+
+// writeFallback writes the code that appends to b the encoding of expr using reflection.
+func (g *generator) writeFallback(expr string) {
+	g.printf("if b, err = sbor.AppendValue(b, %s); err != nil {\nreturn b, err\n}\n", expr)
+}
+
+// writeArray writes the code that appends to b the encoding of the
+// slice or array expr, with elements of type elem.
+func (g *generator) writeArray(expr string, elem types.Type) {
+	value := g.newVar("v")
+	g.printf("if b, err = sbor.AppendArrayHeader(b, len(%s)); err != nil {\nreturn b, err\n}\n", expr)
+	g.printf("for _, %s := range %s {\n", value, expr)
+	g.writeValue(value, elem)
+	g.printf("}\n")
+}
+
+// convert returns expr converted to the basic type kind, if its type t is different.
+func convert(expr string, t types.Type, kind types.BasicKind) string {
+	target := types.Typ[kind]
+	if types.Identical(t, target) {
+		return expr
+	}
+	return target.Name() + "(" + expr + ")"
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerate_Golden(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+
+	pkg, err := loadPackage(dir, "")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	result, err := generate(pkg, []string{"sbor"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected, err := os.ReadFile(filepath.Join(dir, "example_sbor.go"))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Generated code is different from example_sbor.go, run go generate.")
+	}
+}
+
+func TestParseFields_Error(t *testing.T) {
+	stringType := types.Typ[types.String]
+	newField := func(name string, typ types.Type) *types.Var {
+		return types.NewField(0, nil, name, typ, false)
+	}
+
+	data := []struct {
+		fields []*types.Var
+		tags   []string
+		name   string
+	}{
+		{
+			fields: []*types.Var{newField("A", stringType), newField("B", stringType)},
+			tags:   []string{`sbor:"a"`, `sbor:"a"`},
+			name:   "duplicated key",
+		},
+		{
+			fields: []*types.Var{newField("A", stringType)},
+			tags:   []string{`sbor:",customkey"`},
+			name:   "missing setcustomkeys",
+		},
+		{
+			fields: []*types.Var{newField("A", stringType)},
+			tags:   []string{`sbor:",setcustomkeys"`},
+			name:   "invalid setcustomkeys",
+		},
+		{
+			fields: []*types.Var{
+				newField("A", types.NewMap(stringType, stringType)),
+				newField("B", stringType),
+				newField("C", stringType),
+			},
+			tags: []string{`sbor:",setcustomkeys"`, `sbor:"b,customkey"`, `sbor:"b,customkey"`},
+			name: "duplicated custom key",
+		},
+		{
+			fields: []*types.Var{newField("A", types.Typ[types.Float64])},
+			tags:   []string{`sbor:",float=64"`},
+			name:   "number options",
+		},
+		{
+			fields: []*types.Var{newField("A", types.Typ[types.Int64])},
+			tags:   []string{`sbor:",time=unix"`},
+			name:   "time options",
+		},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			s := types.NewStruct(test.fields, test.tags)
+			if _, err := parseFields("Test", s, []string{"sbor"}); err == nil {
+				t.Error("Error was expected.")
+			}
+		})
+	}
+}
+
+func TestParseFields_TagNames(t *testing.T) {
+	stringType := types.Typ[types.String]
+	fields := []*types.Var{
+		types.NewField(0, nil, "A", stringType, false),
+		types.NewField(0, nil, "B", stringType, false),
+		types.NewField(0, nil, "C", stringType, false),
+	}
+	tags := []string{`sbor:"a" json:"x"`, `json:"b,omitempty"`, `json:"-"`}
+
+	result, err := parseFields("Test", types.NewStruct(fields, tags), []string{"sbor", "msgpack", "json"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if len(result) != 2 || result[0].key != "a" || result[1].key != "b" || !result[1].omitEmpty {
+		t.Errorf("Invalid result. Function returned %+v.", result)
+	}
+}
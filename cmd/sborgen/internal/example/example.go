@@ -0,0 +1,122 @@
+// Package example contains the struct types used to test the code generated by sborgen.
+package example
+
+//go:generate go run github.com/ErikPelli/sbor/cmd/sborgen
+
+import (
+	"github.com/ErikPelli/sbor"
+	"time"
+)
+
+//sbor:generate
+type Example struct {
+	Hello      int     `sbor:"-"`
+	F          float64 `sbor:"float64"`
+	Hyphen     string  `sbor:"-,"`
+	Bytes      []byte  `sbor:",omitempty"`
+	Apple      uint    `sbor:"unsigned,omitempty"`
+	unexported bool
+}
+
+//sbor:generate
+type Integers struct {
+	A int8   `sbor:"a"`
+	B uint16 `sbor:"b"`
+	C int32  `sbor:"c"`
+}
+
+//sbor:generate
+type Nested struct {
+	Hyphen string   `sbor:"h"`
+	I      Integers `sbor:"i"`
+}
+
+//sbor:generate
+type IntegersArray struct {
+	A int8   `sbor:"a,structarray"`
+	B uint16 `sbor:"b"`
+	C int32  `sbor:"c"`
+}
+
+//sbor:generate
+type NestedArray struct {
+	Hyphen string        `sbor:"h"`
+	I      IntegersArray `sbor:"i"`
+}
+
+//sbor:generate
+type CustomKeys struct {
+	A map[string]int8 `sbor:",setcustomkeys"`
+	B uint16          `sbor:"b,customkey"`
+	C int32           `sbor:"c,customkey"`
+}
+
+// Celsius is a named type with a basic underlying type.
+type Celsius float32
+
+// Raw is a named byte slice, encoded as an array.
+type Raw []byte
+
+//sbor:generate
+type Types struct {
+	Bool      bool
+	Temp      Celsius
+	NegZero   float64 `sbor:",omitempty"`
+	Time      time.Time
+	Pointer   *Integers
+	NilPtr    *string
+	Strings   []string
+	Raw       Raw
+	Array     [2]uint8
+	Map       map[string][]int
+	Any       interface{}
+	External  struct{ X int }
+	Empty     *Types        `sbor:",omitempty"`
+	ZeroArray [2]int        `sbor:",omitempty"`
+	Keys      map[int]*bool `sbor:"keys"`
+	Grid      map[[2]int]string
+	Message   sbor.RawMessage
+}
+
+//sbor:generate
+type OptionalArray struct {
+	Keys    map[string]interface{} `sbor:",setcustomkeys"`
+	First   string                 `sbor:"first,customkey"`
+	Flag    bool                   `sbor:",omitempty,structarray"`
+	Numbers []int                  `sbor:",omitempty"`
+}
+
+// Period is a type with its own definition of zero value.
+type Period struct {
+	Start, End int
+}
+
+// IsZero reports whether the period is empty.
+func (p Period) IsZero() bool {
+	return p.End <= p.Start
+}
+
+//sbor:generate
+type Optional struct {
+	Slice   []int          `sbor:",omitempty"`
+	Map     map[string]int `sbor:",omitempty,omitzero"`
+	Zero    []int          `sbor:",omitzero"`
+	Time    time.Time      `sbor:",omitzero"`
+	Period  Period         `sbor:",omitzero"`
+	Pointer *Period        `sbor:",omitzero"`
+	Struct  Integers       `sbor:",omitzero"`
+	Text    string         `sbor:",omitempty"`
+	Array   [1]int         `sbor:",omitempty"`
+	Nested  Integers       `sbor:",omitempty"`
+}
+
+//sbor:generate
+type Quoted struct {
+	ID      uint64   `sbor:"id,string"`
+	Offset  *int32   `sbor:"offset,string"`
+	Enabled bool     `sbor:"enabled,string"`
+	Ratio   float32  `sbor:"ratio,string"`
+	Temp    Celsius  `sbor:"temp,string"`
+	Name    string   `sbor:"name,string"`
+	Tags    []string `sbor:"tags,string"`
+}
@@ -0,0 +1,648 @@
+// Code generated by sborgen. DO NOT EDIT.
+
+package example
+
+import (
+	"github.com/ErikPelli/sbor"
+	"reflect"
+	"strconv"
+)
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x Example) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x Example) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	emptyBytes := len(x.Bytes) == 0
+	emptyApple := x.Apple == 0
+	n := 2
+	if !emptyBytes {
+		n++
+	}
+	if !emptyApple {
+		n++
+	}
+	if b, err = sbor.AppendMapHeader(b, n); err != nil {
+		return b, err
+	}
+
+	// F
+	b = append(b, "\xa7float64"...)
+	b = sbor.AppendFloat(b, x.F)
+
+	// Hyphen
+	b = append(b, "\xa1-"...)
+	if b, err = sbor.AppendString(b, x.Hyphen); err != nil {
+		return b, err
+	}
+
+	// Bytes
+	if !emptyBytes {
+		b = append(b, "\xa5Bytes"...)
+		if b, err = sbor.AppendBinary(b, x.Bytes); err != nil {
+			return b, err
+		}
+	}
+
+	// Apple
+	if !emptyApple {
+		b = append(b, "\xa8unsigned"...)
+		b = sbor.AppendUint(b, uint64(x.Apple))
+	}
+
+	return b, nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x Integers) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x Integers) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	if b, err = sbor.AppendMapHeader(b, 3); err != nil {
+		return b, err
+	}
+
+	// A
+	b = append(b, "\xa1a"...)
+	b = sbor.AppendInt(b, int64(x.A))
+
+	// B
+	b = append(b, "\xa1b"...)
+	b = sbor.AppendUint(b, uint64(x.B))
+
+	// C
+	b = append(b, "\xa1c"...)
+	b = sbor.AppendInt(b, int64(x.C))
+
+	return b, nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x Nested) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x Nested) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	if b, err = sbor.AppendMapHeader(b, 2); err != nil {
+		return b, err
+	}
+
+	// Hyphen
+	b = append(b, "\xa1h"...)
+	if b, err = sbor.AppendString(b, x.Hyphen); err != nil {
+		return b, err
+	}
+
+	// I
+	b = append(b, "\xa1i"...)
+	if b, err = x.I.AppendMsgpack(b); err != nil {
+		return b, err
+	}
+
+	return b, nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x IntegersArray) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x IntegersArray) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	if b, err = sbor.AppendArrayHeader(b, 3); err != nil {
+		return b, err
+	}
+
+	// A
+	b = sbor.AppendInt(b, int64(x.A))
+
+	// B
+	b = sbor.AppendUint(b, uint64(x.B))
+
+	// C
+	b = sbor.AppendInt(b, int64(x.C))
+
+	return b, nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x NestedArray) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x NestedArray) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	if b, err = sbor.AppendMapHeader(b, 2); err != nil {
+		return b, err
+	}
+
+	// Hyphen
+	b = append(b, "\xa1h"...)
+	if b, err = sbor.AppendString(b, x.Hyphen); err != nil {
+		return b, err
+	}
+
+	// I
+	b = append(b, "\xa1i"...)
+	if b, err = x.I.AppendMsgpack(b); err != nil {
+		return b, err
+	}
+
+	return b, nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x CustomKeys) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x CustomKeys) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	if b, err = sbor.AppendMapHeader(b, 2); err != nil {
+		return b, err
+	}
+
+	// B
+	if b, err = sbor.AppendCustomKey(b, x.A, "b"); err != nil {
+		return b, err
+	}
+	b = sbor.AppendUint(b, uint64(x.B))
+
+	// C
+	if b, err = sbor.AppendCustomKey(b, x.A, "c"); err != nil {
+		return b, err
+	}
+	b = sbor.AppendInt(b, int64(x.C))
+
+	return b, nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x Types) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x Types) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	emptyNegZero := x.NegZero == 0
+	emptyEmpty := x.Empty == nil
+	emptyZeroArray := false
+	n := 14
+	if !emptyNegZero {
+		n++
+	}
+	if !emptyEmpty {
+		n++
+	}
+	if !emptyZeroArray {
+		n++
+	}
+	if b, err = sbor.AppendMapHeader(b, n); err != nil {
+		return b, err
+	}
+
+	// Bool
+	b = append(b, "\xa4Bool"...)
+	b = sbor.AppendBool(b, x.Bool)
+
+	// Temp
+	b = append(b, "\xa4Temp"...)
+	b = sbor.AppendFloat(b, float64(x.Temp))
+
+	// NegZero
+	if !emptyNegZero {
+		b = append(b, "\xa7NegZero"...)
+		b = sbor.AppendFloat(b, x.NegZero)
+	}
+
+	// Time
+	b = append(b, "\xa4Time"...)
+	b = sbor.AppendTime(b, x.Time)
+
+	// Pointer
+	b = append(b, "\xa7Pointer"...)
+	if x.Pointer == nil {
+		b = sbor.AppendNil(b)
+	} else {
+		if b, err = (*x.Pointer).AppendMsgpack(b); err != nil {
+			return b, err
+		}
+	}
+
+	// NilPtr
+	b = append(b, "\xa6NilPtr"...)
+	if x.NilPtr == nil {
+		b = sbor.AppendNil(b)
+	} else {
+		if b, err = sbor.AppendString(b, (*x.NilPtr)); err != nil {
+			return b, err
+		}
+	}
+
+	// Strings
+	b = append(b, "\xa7Strings"...)
+	if b, err = sbor.AppendArrayHeader(b, len(x.Strings)); err != nil {
+		return b, err
+	}
+	for _, v1 := range x.Strings {
+		if b, err = sbor.AppendString(b, v1); err != nil {
+			return b, err
+		}
+	}
+
+	// Raw
+	b = append(b, "\xa3Raw"...)
+	if b, err = sbor.AppendArrayHeader(b, len(x.Raw)); err != nil {
+		return b, err
+	}
+	for _, v2 := range x.Raw {
+		b = sbor.AppendUint(b, uint64(v2))
+	}
+
+	// Array
+	b = append(b, "\xa5Array"...)
+	if b, err = sbor.AppendArrayHeader(b, len(x.Array)); err != nil {
+		return b, err
+	}
+	for _, v3 := range x.Array {
+		b = sbor.AppendUint(b, uint64(v3))
+	}
+
+	// Map
+	b = append(b, "\xa3Map"...)
+	if b, err = sbor.AppendMapHeader(b, len(x.Map)); err != nil {
+		return b, err
+	}
+	for k4, v5 := range x.Map {
+		if b, err = sbor.AppendString(b, k4); err != nil {
+			return b, err
+		}
+		if b, err = sbor.AppendArrayHeader(b, len(v5)); err != nil {
+			return b, err
+		}
+		for _, v6 := range v5 {
+			b = sbor.AppendInt(b, int64(v6))
+		}
+	}
+
+	// Any
+	b = append(b, "\xa3Any"...)
+	if b, err = sbor.AppendValue(b, x.Any); err != nil {
+		return b, err
+	}
+
+	// External
+	b = append(b, "\xa8External"...)
+	if b, err = sbor.AppendValue(b, x.External); err != nil {
+		return b, err
+	}
+
+	// Empty
+	if !emptyEmpty {
+		b = append(b, "\xa5Empty"...)
+		if x.Empty == nil {
+			b = sbor.AppendNil(b)
+		} else {
+			if b, err = (*x.Empty).AppendMsgpack(b); err != nil {
+				return b, err
+			}
+		}
+	}
+
+	// ZeroArray
+	if !emptyZeroArray {
+		b = append(b, "\xa9ZeroArray"...)
+		if b, err = sbor.AppendArrayHeader(b, len(x.ZeroArray)); err != nil {
+			return b, err
+		}
+		for _, v7 := range x.ZeroArray {
+			b = sbor.AppendInt(b, int64(v7))
+		}
+	}
+
+	// Keys
+	b = append(b, "\xa4keys"...)
+	if b, err = sbor.AppendMapHeader(b, len(x.Keys)); err != nil {
+		return b, err
+	}
+	for k8, v9 := range x.Keys {
+		b = sbor.AppendInt(b, int64(k8))
+		if v9 == nil {
+			b = sbor.AppendNil(b)
+		} else {
+			b = sbor.AppendBool(b, (*v9))
+		}
+	}
+
+	// Grid
+	b = append(b, "\xa4Grid"...)
+	if b, err = sbor.AppendValue(b, x.Grid); err != nil {
+		return b, err
+	}
+
+	// Message
+	b = append(b, "\xa7Message"...)
+	if b, err = sbor.AppendValue(b, x.Message); err != nil {
+		return b, err
+	}
+
+	return b, nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x OptionalArray) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x OptionalArray) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	emptyFlag := !x.Flag
+	emptyNumbers := len(x.Numbers) == 0
+	n := 1
+	if !emptyFlag {
+		n++
+	}
+	if !emptyNumbers {
+		n++
+	}
+	asArray := false
+	if !emptyFlag {
+		asArray = true
+	}
+	if asArray {
+		b, err = sbor.AppendArrayHeader(b, n)
+	} else {
+		b, err = sbor.AppendMapHeader(b, n)
+	}
+	if err != nil {
+		return b, err
+	}
+
+	// First
+	if asArray {
+		_, err = sbor.AppendCustomKey(nil, x.Keys, "first")
+	} else {
+		b, err = sbor.AppendCustomKey(b, x.Keys, "first")
+	}
+	if err != nil {
+		return b, err
+	}
+	if b, err = sbor.AppendString(b, x.First); err != nil {
+		return b, err
+	}
+
+	// Flag
+	if !emptyFlag {
+		if !asArray {
+			b = append(b, "\xa4Flag"...)
+		}
+		b = sbor.AppendBool(b, x.Flag)
+	}
+
+	// Numbers
+	if !emptyNumbers {
+		if !asArray {
+			b = append(b, "\xa7Numbers"...)
+		}
+		if b, err = sbor.AppendArrayHeader(b, len(x.Numbers)); err != nil {
+			return b, err
+		}
+		for _, v1 := range x.Numbers {
+			b = sbor.AppendInt(b, int64(v1))
+		}
+	}
+
+	return b, nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x Optional) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x Optional) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	emptySlice := len(x.Slice) == 0
+	emptyMap := len(x.Map) == 0 || x.Map == nil
+	emptyZero := x.Zero == nil
+	emptyTime := x.Time.IsZero()
+	emptyPeriod := x.Period.IsZero()
+	emptyPointer := x.Pointer == nil || x.Pointer.IsZero()
+	emptyStruct := reflect.ValueOf(x.Struct).IsZero()
+	emptyText := x.Text == ""
+	emptyArray := false
+	emptyNested := false
+	n := 0
+	if !emptySlice {
+		n++
+	}
+	if !emptyMap {
+		n++
+	}
+	if !emptyZero {
+		n++
+	}
+	if !emptyTime {
+		n++
+	}
+	if !emptyPeriod {
+		n++
+	}
+	if !emptyPointer {
+		n++
+	}
+	if !emptyStruct {
+		n++
+	}
+	if !emptyText {
+		n++
+	}
+	if !emptyArray {
+		n++
+	}
+	if !emptyNested {
+		n++
+	}
+	if b, err = sbor.AppendMapHeader(b, n); err != nil {
+		return b, err
+	}
+
+	// Slice
+	if !emptySlice {
+		b = append(b, "\xa5Slice"...)
+		if b, err = sbor.AppendArrayHeader(b, len(x.Slice)); err != nil {
+			return b, err
+		}
+		for _, v1 := range x.Slice {
+			b = sbor.AppendInt(b, int64(v1))
+		}
+	}
+
+	// Map
+	if !emptyMap {
+		b = append(b, "\xa3Map"...)
+		if b, err = sbor.AppendMapHeader(b, len(x.Map)); err != nil {
+			return b, err
+		}
+		for k2, v3 := range x.Map {
+			if b, err = sbor.AppendString(b, k2); err != nil {
+				return b, err
+			}
+			b = sbor.AppendInt(b, int64(v3))
+		}
+	}
+
+	// Zero
+	if !emptyZero {
+		b = append(b, "\xa4Zero"...)
+		if b, err = sbor.AppendArrayHeader(b, len(x.Zero)); err != nil {
+			return b, err
+		}
+		for _, v4 := range x.Zero {
+			b = sbor.AppendInt(b, int64(v4))
+		}
+	}
+
+	// Time
+	if !emptyTime {
+		b = append(b, "\xa4Time"...)
+		b = sbor.AppendTime(b, x.Time)
+	}
+
+	// Period
+	if !emptyPeriod {
+		b = append(b, "\xa6Period"...)
+		if b, err = sbor.AppendValue(b, x.Period); err != nil {
+			return b, err
+		}
+	}
+
+	// Pointer
+	if !emptyPointer {
+		b = append(b, "\xa7Pointer"...)
+		if x.Pointer == nil {
+			b = sbor.AppendNil(b)
+		} else {
+			if b, err = sbor.AppendValue(b, (*x.Pointer)); err != nil {
+				return b, err
+			}
+		}
+	}
+
+	// Struct
+	if !emptyStruct {
+		b = append(b, "\xa6Struct"...)
+		if b, err = x.Struct.AppendMsgpack(b); err != nil {
+			return b, err
+		}
+	}
+
+	// Text
+	if !emptyText {
+		b = append(b, "\xa4Text"...)
+		if b, err = sbor.AppendString(b, x.Text); err != nil {
+			return b, err
+		}
+	}
+
+	// Array
+	if !emptyArray {
+		b = append(b, "\xa5Array"...)
+		if b, err = sbor.AppendArrayHeader(b, len(x.Array)); err != nil {
+			return b, err
+		}
+		for _, v5 := range x.Array {
+			b = sbor.AppendInt(b, int64(v5))
+		}
+	}
+
+	// Nested
+	if !emptyNested {
+		b = append(b, "\xa6Nested"...)
+		if b, err = x.Nested.AppendMsgpack(b); err != nil {
+			return b, err
+		}
+	}
+
+	return b, nil
+}
+
+// MarshalMsgpack returns the MessagePack encoding of x.
+func (x Quoted) MarshalMsgpack() ([]byte, error) {
+	return x.AppendMsgpack(nil)
+}
+
+// AppendMsgpack appends the MessagePack encoding of x to b.
+func (x Quoted) AppendMsgpack(b []byte) ([]byte, error) {
+	var err error
+	if b, err = sbor.AppendMapHeader(b, 7); err != nil {
+		return b, err
+	}
+
+	// ID
+	b = append(b, "\xa2id"...)
+	if b, err = sbor.AppendString(b, strconv.FormatUint(x.ID, 10)); err != nil {
+		return b, err
+	}
+
+	// Offset
+	b = append(b, "\xa6offset"...)
+	if x.Offset == nil {
+		b = sbor.AppendNil(b)
+	} else {
+		if b, err = sbor.AppendString(b, strconv.FormatInt(int64((*x.Offset)), 10)); err != nil {
+			return b, err
+		}
+	}
+
+	// Enabled
+	b = append(b, "\xa7enabled"...)
+	if b, err = sbor.AppendString(b, strconv.FormatBool(x.Enabled)); err != nil {
+		return b, err
+	}
+
+	// Ratio
+	b = append(b, "\xa5ratio"...)
+	if b, err = sbor.AppendString(b, strconv.FormatFloat(float64(x.Ratio), 'g', -1, 32)); err != nil {
+		return b, err
+	}
+
+	// Temp
+	b = append(b, "\xa4temp"...)
+	if b, err = sbor.AppendString(b, strconv.FormatFloat(float64(x.Temp), 'g', -1, 32)); err != nil {
+		return b, err
+	}
+
+	// Name
+	b = append(b, "\xa4name"...)
+	if b, err = sbor.AppendString(b, x.Name); err != nil {
+		return b, err
+	}
+
+	// Tags
+	b = append(b, "\xa4tags"...)
+	if b, err = sbor.AppendArrayHeader(b, len(x.Tags)); err != nil {
+		return b, err
+	}
+	for _, v1 := range x.Tags {
+		if b, err = sbor.AppendString(b, v1); err != nil {
+			return b, err
+		}
+	}
+
+	return b, nil
+}
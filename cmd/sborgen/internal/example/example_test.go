@@ -0,0 +1,122 @@
+package example
+
+import (
+	"bytes"
+	"github.com/ErikPelli/sbor"
+	"testing"
+	"time"
+)
+
+type generatedEncoder interface {
+	MarshalMsgpack() ([]byte, error)
+	AppendMsgpack(b []byte) ([]byte, error)
+}
+
+func TestGenerated_Equal_Reflection(t *testing.T) {
+	flag := true
+	offset := int32(-40000)
+	data := []struct {
+		input generatedEncoder
+		name  string
+	}{
+		{input: Example{Hello: 66, F: 9.5, Hyphen: "hyphen", Apple: 32}, name: "example struct"},
+		{input: Example{F: 1.37, Bytes: []byte{0x01, 0x02}}, name: "example struct bytes"},
+		{input: Nested{Hyphen: "hyphen", I: Integers{A: -8, B: 32000, C: -40000}}, name: "nested struct"},
+		{input: NestedArray{Hyphen: "hyphen", I: IntegersArray{A: -8, B: 32000, C: -40000}}, name: "nested array struct"},
+		{input: CustomKeys{A: map[string]int8{"b": -8, "c": 38}, B: 32000, C: -40000}, name: "custom key"},
+		{input: Types{
+			Bool:      true,
+			Temp:      21.5,
+			NegZero:   2.5,
+			Time:      time.Unix(1646580000, 12345),
+			Pointer:   &Integers{A: 1, B: 2, C: 3},
+			Strings:   []string{"foo", "bar"},
+			Raw:       Raw{0x01, 0xFF},
+			Array:     [2]uint8{4, 5},
+			Map:       map[string][]int{"int": {1, -1000}},
+			Any:       []interface{}{123, nil, 5.76},
+			External:  struct{ X int }{X: 7},
+			Empty:     &Types{},
+			ZeroArray: [2]int{0, 1},
+			Keys:      map[int]*bool{-5: &flag},
+			Grid:      map[[2]int]string{{1, 2}: "a", {0, 5}: "b", {1, -1}: "c"},
+			Message:   sbor.RawMessage{0x92, 0xC3, 0xC0},
+		}, name: "types"},
+		{input: Types{}, name: "zero types"},
+		{input: OptionalArray{Keys: map[string]interface{}{"first": 1.5}, First: "first"}, name: "optional array as map"},
+		{input: Optional{Slice: []int{}, Map: map[string]int{}, Period: Period{Start: 2, End: 1}, Pointer: &Period{}}, name: "omitted optional"},
+		{input: Optional{
+			Slice:   []int{1},
+			Map:     map[string]int{"a": 1},
+			Zero:    []int{},
+			Time:    time.Unix(0, 0),
+			Period:  Period{Start: 1, End: 2},
+			Pointer: &Period{End: 1},
+			Struct:  Integers{A: 1},
+			Text:    "text",
+			Array:   [1]int{1},
+			Nested:  Integers{C: 3},
+		}, name: "optional"},
+		{input: Quoted{ID: 18446744073709551615, Offset: &offset, Enabled: true, Ratio: 0.1, Temp: -3.5, Name: "n", Tags: []string{"t"}}, name: "quoted"},
+		{input: Quoted{}, name: "zero quoted"},
+		{input: OptionalArray{Keys: map[string]interface{}{"first": 1.5}, First: "first", Flag: true, Numbers: []int{9}}, name: "optional array as array"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			expected, err := sbor.Marshal(test.input)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			result, err := test.input.MarshalMsgpack()
+			if err != nil {
+				t.Error(err.Error())
+			}
+			if !bytes.Equal(result, expected) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result, expected)
+			}
+
+			prefix := []byte{0xC0}
+			result, err = test.input.AppendMsgpack(prefix)
+			if err != nil {
+				t.Error(err.Error())
+			}
+			if !bytes.Equal(result, append(prefix, expected...)) {
+				t.Errorf("Invalid appended result. Function returned %v. Expected %v.", result, expected)
+			}
+		})
+	}
+}
+
+func TestGenerated_NegativeZero(t *testing.T) {
+	negZero := Types{NegZero: 1}
+	negZero.NegZero = -negZero.NegZero * 0
+
+	expected, _ := sbor.Marshal(negZero)
+	result, err := negZero.MarshalMsgpack()
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", result, expected)
+	}
+}
+
+func TestGenerated_CustomKey_Error(t *testing.T) {
+	data := []generatedEncoder{
+		CustomKeys{A: map[string]int8{"b": -8}},
+		OptionalArray{Flag: true},
+	}
+
+	for _, input := range data {
+		if _, err := sbor.Marshal(input); err == nil {
+			t.Error("Reflection error was expected.")
+		}
+
+		if _, err := input.MarshalMsgpack(); err == nil {
+			t.Error("Generated error was expected.")
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// generatedHeader is the first line of every file written by sborgen.
+const generatedHeader = "// Code generated by sborgen. DO NOT EDIT."
+
+// generateDirective marks a struct type declaration for the generation.
+const generateDirective = "//sbor:generate"
+
+// sourcePackage is a parsed and type-checked Go package.
+type sourcePackage struct {
+	types   *types.Package
+	targets []*types.Named // Annotated types, in source order
+}
+
+// loadPackage parses and type-checks the Go package in dir, skipping the files
+// previously generated by sborgen, and collects the annotated struct types.
+func loadPackage(dir string, output string) (*sourcePackage, error) {
+	buildPkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+
+	for _, name := range buildPkg.GoFiles {
+		path := filepath.Join(dir, name)
+		if output != "" && sameFile(path, output) {
+			continue
+		}
+
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(src, []byte(generatedHeader)) {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	// The package may reference the methods we are going to generate,
+	// so type errors are tolerated as long as the annotated types are resolved.
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(buildPkg.ImportPath, fset, files, nil)
+
+	result := &sourcePackage{types: pkg}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if !hasDirective(typeSpec.Doc) && !(len(genDecl.Specs) == 1 && hasDirective(genDecl.Doc)) {
+					continue
+				}
+
+				named, err := lookupStruct(pkg, typeSpec.Name.Name)
+				if err != nil {
+					return nil, fmt.Errorf("%v: %w", fset.Position(typeSpec.Pos()), err)
+				}
+				result.targets = append(result.targets, named)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// hasDirective reports whether the comment group contains the generate directive.
+func hasDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == generateDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupStruct returns the named struct type declared in pkg with the given name.
+func lookupStruct(pkg *types.Package, name string) (*types.Named, error) {
+	typeName, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", name)
+	}
+
+	named, ok := typeName.Type().(*types.Named)
+	if !ok || typeName.IsAlias() {
+		return nil, fmt.Errorf("%s is not a defined type", name)
+	}
+	if named.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("generic type %s is not supported", name)
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil, fmt.Errorf("%s is not a struct type", name)
+	}
+
+	return named, nil
+}
+
+// sameFile reports whether the two paths refer to the same file.
+func sameFile(a string, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
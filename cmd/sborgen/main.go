@@ -0,0 +1,83 @@
+// Command sborgen generates reflection-free MessagePack encoders for Go structs.
+//
+// It reads the Go package in the given directory (the current one by default) and,
+// for every struct type whose declaration is preceded by a
+//
+//	//sbor:generate
+//
+// comment, it writes the following methods:
+//
+//	func (x T) MarshalMsgpack() ([]byte, error)
+//	func (x T) AppendMsgpack(b []byte) ([]byte, error)
+//
+// The generated methods emit exactly the same bytes as sbor.Marshal, honoring
+// the "sbor" struct tags ("-", renaming, omitempty, omitzero, string, structarray, setcustomkeys
+// and customkey), but without using reflection for the fields with a known type.
+// Fields whose type can't be handled statically (interfaces, channels, structs
+// from other packages, ...) fall back to sbor.AppendValue.
+// The numbers and the times are always written with the default formats of sbor.Marshal,
+// so the float, int, signed and time field options are rejected.
+//
+// Usage:
+//
+//	sborgen [-o output] [-tags sbor,msgpack,json] [dir]
+//
+// The -tags flag sets the keys of the struct tags to read, in order of precedence,
+// like Encoder.SetTagName: the generated methods emit the same bytes as an Encoder
+// with the same keys. By default only the "sbor" key is read.
+//
+// By default the methods are written to the file <package>_sbor.go in the package
+// directory. It is usually invoked through a go:generate directive:
+//
+//	//go:generate sborgen
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	output := flag.String("o", "", "output file name (default <package>_sbor.go in the package directory)")
+	tags := flag.String("tags", "sbor", "comma-separated keys of the struct tags, in order of precedence")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "usage: sborgen [-o output] [-tags keys] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		dir = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(dir, *output, strings.Split(*tags, ",")); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "sborgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir string, output string, tagNames []string) error {
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg, tagNames)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = filepath.Join(dir, pkg.types.Name()+"_sbor.go")
+	}
+	return os.WriteFile(output, src, 0644)
+}
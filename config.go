@@ -0,0 +1,230 @@
+package sbor
+
+import (
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
+)
+
+// A Config is an immutable set of encoding and decoding options, that can be shared
+// by the whole program. It is built once, registering the external types and choosing
+// the options with the With methods, that return a new Config and leave the receiver
+// unchanged. Then Marshal, Unmarshal, NewEncoder and NewDecoder use its options,
+// and they are safe for concurrent use by multiple goroutines.
+//
+// For example:
+//
+//   var codec = sbor.NewConfig().WithTagName("sbor", "json").WithCanonical(true)
+//
+//   func handler(w http.ResponseWriter, r *http.Request) {
+//       ...
+//       err := codec.NewEncoder(w).Encode(response)
+//   }
+type Config struct {
+	encoder *encode.EncoderState
+	decoder *decode.DecoderState
+}
+
+// NewConfig returns a Config with the default options, the same used by Marshal and Unmarshal.
+func NewConfig() *Config {
+	return &Config{
+		encoder: encode.NewEncoderState(),
+		decoder: decode.NewDecoderState(),
+	}
+}
+
+// copy returns a copy of c that can be modified.
+func (c *Config) copy() *Config {
+	return &Config{
+		encoder: c.encoder.Copy(),
+		decoder: c.decoder.Copy(),
+	}
+}
+
+// WithExternalType returns a copy of c that associates a type with an external type code
+// when encoding. If a pointer to the type implements MessagePackCustom, the association is
+// used also when decoding. See the documentation of Encoder.SetExternalType for details.
+func (c *Config) WithExternalType(id int8, value interface{}, e ...CustomEncoder) (*Config, error) {
+	result := c.copy()
+	if err := setExternalType(result.encoder, id, value, e...); err != nil {
+		return nil, err
+	}
+	if len(e) == 0 {
+		if err := setExternalDecoder(result.decoder, id, value); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// WithExternalDecoder returns a copy of c that associates a type with an external type code
+// when decoding. See the documentation of Decoder.SetExternalType for details.
+func (c *Config) WithExternalDecoder(id int8, value interface{}, d ...CustomDecoder) (*Config, error) {
+	result := c.copy()
+	if err := setExternalDecoder(result.decoder, id, value, d...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WithExternalInterface returns a copy of c that associates an interface with an external type code.
+// See the documentation of Encoder.SetExternalInterface for details.
+func (c *Config) WithExternalInterface(id int8, pointerToInterface interface{}, e ...CustomEncoder) (*Config, error) {
+	result := c.copy()
+	if err := setExternalInterface(result.encoder, id, pointerToInterface, e...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WithTagName returns a copy of c that reads the given keys of the struct field's tag,
+// when encoding and when decoding. See the documentation of Encoder.SetTagName for details.
+func (c *Config) WithTagName(names ...string) *Config {
+	result := c.copy()
+	result.encoder.TagNames = tagNames(names)
+	result.decoder.TagNames = tagNames(names)
+	return result
+}
+
+// WithFloatPolicy returns a copy of c that writes the floating point numbers with the given format.
+// See the documentation of Encoder.SetFloatPolicy for details.
+func (c *Config) WithFloatPolicy(p FloatPolicy) *Config {
+	result := c.copy()
+	result.encoder.Numbers.Float = p
+	return result
+}
+
+// WithIntPolicy returns a copy of c that writes the integers with the given format.
+// See the documentation of Encoder.SetIntPolicy for details.
+func (c *Config) WithIntPolicy(p IntPolicy) *Config {
+	result := c.copy()
+	result.encoder.Numbers.Int = p
+	return result
+}
+
+// WithPreserveSign returns a copy of c that writes the values of the signed Go integer types
+// with the int formats. See the documentation of Encoder.SetPreserveSign for details.
+func (c *Config) WithPreserveSign(preserve bool) *Config {
+	result := c.copy()
+	result.encoder.Numbers.PreserveSign = preserve
+	return result
+}
+
+// WithCompatMode returns a copy of c that writes only the formats of the old MessagePack
+// specification, and reads the strings as raw bytes. See the documentation of
+// Encoder.SetCompatMode and Decoder.SetCompatMode for details.
+func (c *Config) WithCompatMode(compat bool) *Config {
+	result := c.copy()
+	result.encoder.CompatMode = compat
+	result.decoder.CompatMode = compat
+	return result
+}
+
+// WithCanonical returns a copy of c that sorts the map keys when encoding.
+// See the documentation of Encoder.SetCanonical for details.
+func (c *Config) WithCanonical(canonical bool) *Config {
+	result := c.copy()
+	result.encoder.Canonical = canonical
+	return result
+}
+
+// WithTimePolicy returns a copy of c that writes the time.Time values with the given format,
+// and reads the integer times with its unit. See the documentation of Encoder.SetTimePolicy
+// and Decoder.SetTimePolicy for details.
+func (c *Config) WithTimePolicy(p TimePolicy) *Config {
+	result := c.copy()
+	result.encoder.Time = p
+	result.decoder.Time = p
+	return result
+}
+
+// WithZonedTimeType returns a copy of c that uses the given external type code for the times
+// with their zone, when encoding and when decoding. See the documentation of Encoder.SetZonedTimeType.
+func (c *Config) WithZonedTimeType(id int8) (*Config, error) {
+	if id < 0 {
+		return nil, utils.OutOfBoundError{Key: int(id)}
+	}
+	result := c.copy()
+	result.encoder.ZonedTimeType = id
+	result.decoder.ZonedTimeType = id
+	return result, nil
+}
+
+// WithDuplicateKeys returns a copy of c that handles the keys repeated in a map with the given
+// policy when decoding. See the documentation of Decoder.SetDuplicateKeys for details.
+func (c *Config) WithDuplicateKeys(p DuplicatePolicy) *Config {
+	result := c.copy()
+	result.decoder.DuplicateKeys = p
+	return result
+}
+
+// WithDisallowUnknownFields returns a copy of c that returns an error for the map keys that don't
+// match any struct field when decoding. See the documentation of Decoder.DisallowUnknownFields.
+func (c *Config) WithDisallowUnknownFields() *Config {
+	result := c.copy()
+	result.decoder.DisallowUnknownFields = true
+	return result
+}
+
+// WithNoCopy returns a copy of c whose decoded byte slices reference the input instead of a copy of it.
+// See the documentation of UnmarshalNoCopy and Decoder.SetNoCopy for details.
+func (c *Config) WithNoCopy(noCopy bool) *Config {
+	result := c.copy()
+	result.decoder.NoCopy = noCopy
+	return result
+}
+
+// WithInternKeys returns a copy of c that reuses the same string for equal string keys when decoding.
+// See the documentation of Decoder.SetInternKeys for details.
+func (c *Config) WithInternKeys(intern bool) *Config {
+	result := c.copy()
+	result.decoder.InternKeys = intern
+	return result
+}
+
+// WithMaxDepth returns a copy of c that limits the nesting depth when decoding.
+// See the documentation of Decoder.SetMaxDepth for details.
+func (c *Config) WithMaxDepth(depth int) *Config {
+	result := c.copy()
+	result.decoder.MaxDepth = depth
+	return result
+}
+
+// WithMaxLength returns a copy of c that limits the length of the objects when decoding.
+// See the documentation of Decoder.SetMaxLength for details.
+func (c *Config) WithMaxLength(length int) *Config {
+	result := c.copy()
+	result.decoder.MaxLength = length
+	return result
+}
+
+// Marshal returns the MessagePack encoding of v, using the options of c.
+// See the documentation for the Marshal function for details.
+func (c *Config) Marshal(v interface{}) ([]byte, error) {
+	return marshal(c.encoder, v)
+}
+
+// Unmarshal parses the MessagePack data and stores the result in the value pointed to by v,
+// using the options of c. See the documentation for the Unmarshal function for details.
+func (c *Config) Unmarshal(data []byte, v interface{}) error {
+	return unmarshal(c.decoder.Copy(), data, v)
+}
+
+// NewEncoder returns a new encoder that writes to w, using the options of c.
+// The options of the Encoder can be changed without affecting c.
+func (c *Config) NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w:     w,
+		state: c.encoder.Copy(),
+	}
+}
+
+// NewDecoder returns a new decoder that reads from r, using the options of c.
+// The options of the Decoder can be changed without affecting c.
+func (c *Config) NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:     r,
+		state: c.decoder.Copy(),
+	}
+}
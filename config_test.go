@@ -0,0 +1,261 @@
+package sbor
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfig(t *testing.T) {
+	type Test struct {
+		Name   string              `json:"name"`
+		Custom *TestExternalCustom `json:"custom"`
+		Map    map[string]int      `json:"map"`
+	}
+
+	base := NewConfig()
+	config, err := base.WithExternalType(0x10, &TestExternalCustom{})
+	if err != nil {
+		t.Fatalf("WithExternalType Error: %v", err)
+	}
+	config = config.WithTagName("json").WithCanonical(true)
+
+	input := Test{Name: "n", Custom: &TestExternalCustom{"x"}, Map: map[string]int{"c": 3, "a": 1, "b": 2}}
+	result, err := config.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+
+	expected := mustParseDiag(t, `{"name": "n", "custom": ext(16, h'78'), "map": {"a": 1, "b": 2, "c": 3}}`)
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", result, expected)
+	}
+
+	// The base Config is unchanged
+	if baseResult, err := base.Marshal(input); err != nil || bytes.Equal(baseResult, expected) {
+		t.Errorf("The base Config was modified. Function returned %v, %v.", baseResult, err)
+	}
+
+	var decoded struct {
+		Name string `json:"name"`
+	}
+	if err = config.Unmarshal(result, &decoded); err != nil || decoded.Name != "n" {
+		t.Errorf("Invalid result. Function returned %+v, %v.", decoded, err)
+	}
+
+	if _, err = base.WithExternalType(0x10, 1); err == nil {
+		t.Error("Error was expected without CustomEncoder.")
+	}
+}
+
+func TestConfig_TimePolicy(t *testing.T) {
+	config, err := NewConfig().WithTimePolicy(TimeZoned).WithZonedTimeType(5)
+	if err != nil {
+		t.Fatalf("WithZonedTimeType Error: %v", err)
+	}
+
+	input := time.Date(2000, 1, 1, 0, 0, 0, 0, time.FixedZone("X", 3600))
+	result, err := config.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+
+	expected := mustParseDiag(t, "ext(5, h'00000000386d35700000000000000e1058')")
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", result, expected)
+	}
+
+	var decoded time.Time
+	if err = config.Unmarshal(result, &decoded); err != nil || !decoded.Equal(input) || decoded.Location().String() != "X" {
+		t.Errorf("Invalid result. Function returned %v, %v.", decoded, err)
+	}
+
+	if _, err = config.WithZonedTimeType(-1); err == nil {
+		t.Error("Error was expected with a negative external type code.")
+	}
+}
+
+func TestConfig_DecoderOptions(t *testing.T) {
+	base := NewConfig()
+	config := base.WithCompatMode(true).WithNoCopy(true).WithInternKeys(true)
+	data := mustParseDiag(t, `{"key": "value", "bin": h'0102'}`)
+
+	var result map[string]interface{}
+	if err := config.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+	expected := map[string]interface{}{"key": []byte("value"), "bin": []byte{0x01, 0x02}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Invalid result. Function returned %#v. Expected %#v.", result, expected)
+	}
+
+	// The binary payload references data
+	data[len(data)-1] = 0x03
+	if bin := result["bin"].([]byte); bin[1] != 0x03 {
+		t.Errorf("The payload was copied. Function returned %v.", bin)
+	}
+
+	d := config.NewDecoder(bytes.NewReader(data))
+	if !d.state.CompatMode || !d.state.NoCopy || !d.state.InternKeys {
+		t.Errorf("Invalid Decoder options. Function returned %+v.", d.state)
+	}
+	if base.decoder.CompatMode || base.decoder.NoCopy || base.decoder.InternKeys {
+		t.Errorf("The base Config was modified. Function returned %+v.", base.decoder)
+	}
+}
+
+func TestConfig_DuplicateKeys(t *testing.T) {
+	config := NewConfig().WithDuplicateKeys(DuplicateError)
+	data := mustParseDiag(t, `{"a": 1, "a": 2}`)
+
+	var result map[string]int
+	var duplicated DuplicatedKeyError
+	if err := config.Unmarshal(data, &result); !errors.As(err, &duplicated) {
+		t.Errorf("DuplicatedKeyError was expected. Function returned %v.", err)
+	}
+	if err := NewConfig().Unmarshal(data, &result); err != nil || result["a"] != 2 {
+		t.Errorf("Invalid result. Function returned %v, %v.", result, err)
+	}
+}
+
+func TestConfig_DisallowUnknownFields(t *testing.T) {
+	var result struct {
+		A int
+	}
+	data := mustParseDiag(t, `{"A": 1, "B": 2}`)
+
+	var unknown UnknownFieldError
+	if err := NewConfig().WithDisallowUnknownFields().Unmarshal(data, &result); !errors.As(err, &unknown) {
+		t.Errorf("UnknownFieldError was expected. Function returned %v.", err)
+	}
+	if err := NewConfig().Unmarshal(data, &result); err != nil || result.A != 1 {
+		t.Errorf("Invalid result. Function returned %+v, %v.", result, err)
+	}
+}
+
+func TestConfig_Numbers(t *testing.T) {
+	type point struct {
+		X int
+	}
+
+	config := NewConfig().WithFloatPolicy(FloatPreserve).WithIntPolicy(IntShortest).WithPreserveSign(true).WithCanonical(true)
+
+	input := map[point]interface{}{{2}: float64(1.5), {1}: 5}
+	result, err := config.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+
+	expected := []byte{0x82, 0x81, 0xA1, 0x58, 0xD0, 0x01, 0xD0, 0x05, 0x81, 0xA1, 0x58, 0xD0, 0x02,
+		0xCB, 0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", result, expected)
+	}
+
+	// Binary written as a raw string
+	compat := config.WithCompatMode(true)
+	if result, err = compat.Marshal([]byte{0x01}); err != nil || !bytes.Equal(result, []byte{0xA1, 0x01}) {
+		t.Errorf("Invalid result. Function returned %v, %v.", result, err)
+	}
+	if _, err = compat.Marshal(time.Unix(0, 0)); err == nil {
+		t.Error("Error was expected for a time in compatibility mode.")
+	}
+	if result, err = config.Marshal([]byte{0x01}); err != nil || !bytes.Equal(result, []byte{0xC4, 0x01, 0x01}) {
+		t.Errorf("The base Config was modified. Function returned %v, %v.", result, err)
+	}
+}
+
+func TestConfig_Limits(t *testing.T) {
+	config := NewConfig().WithMaxDepth(2).WithMaxLength(3)
+
+	data := []struct {
+		input string
+		valid bool
+	}{
+		{input: "[[1, 2, 3]]", valid: true},
+		{input: "[[[1]]]", valid: false},
+		{input: "[1, 2, 3, 4]", valid: false},
+		{input: `"abcd"`, valid: false},
+		{input: `{"a": {"b": "c"}}`, valid: true},
+	}
+
+	for _, test := range data {
+		var v interface{}
+		err := config.Unmarshal(mustParseDiag(t, test.input), &v)
+		if (err == nil) != test.valid {
+			t.Errorf("Invalid result for %s. Function returned %v.", test.input, err)
+		}
+
+		var b bytes.Buffer
+		b.Write(mustParseDiag(t, test.input))
+		d := NewDecoder(&b)
+		d.SetMaxDepth(2)
+		d.SetMaxLength(3)
+		var s []interface{}
+		if err = d.Decode(&s); (err == nil) != test.valid && test.input[0] == '[' {
+			t.Errorf("Invalid Decoder result for %s. Function returned %v.", test.input, err)
+		}
+	}
+}
+
+func TestConfig_Concurrent(t *testing.T) {
+	config, err := NewConfig().WithExternalType(0x10, &TestExternalCustom{})
+	if err != nil {
+		t.Fatalf("WithExternalType Error: %v", err)
+	}
+	config = config.WithCanonical(true)
+
+	input := map[string]interface{}{"custom": &TestExternalCustom{"x"}, "list": []int{1, 2}, "name": "n"}
+	expected, err := config.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+
+	// Run with the race detector to check that a Config can be shared
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				result, err := config.Marshal(input)
+				if err != nil || !bytes.Equal(result, expected) {
+					t.Errorf("Invalid result. Function returned %v, %v. Expected %v.", result, err, expected)
+					return
+				}
+
+				var b bytes.Buffer
+				e := config.NewEncoder(&b)
+				if err = e.SetExternalType(0x11, complex64(0), CustomEncoder{Encoder: func(i interface{}) ([]byte, error) {
+					return []byte{0x01}, nil
+				}}); err != nil {
+					t.Errorf("SetExternalType Error: %v", err)
+					return
+				}
+				if err = e.Encode(input); err != nil {
+					t.Errorf("Encoder Error: %v", err)
+					return
+				}
+
+				var decoded struct {
+					List []int  `sbor:"list"`
+					Name string `sbor:"name"`
+				}
+				d := config.NewDecoder(&b)
+				d.SetInternKeys(true)
+				if err = d.Decode(&decoded); err != nil || !reflect.DeepEqual(decoded.List, []int{1, 2}) {
+					t.Errorf("Invalid result. Function returned %v, %v.", decoded, err)
+					return
+				}
+				if err = config.Unmarshal(expected, &decoded); err != nil {
+					t.Errorf("Unmarshal Error: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
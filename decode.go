@@ -0,0 +1,106 @@
+package sbor
+
+import (
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
+)
+
+// Unmarshal parses the MessagePack data, that must contain exactly one object, and stores
+// the result in the value pointed to by v. If v is nil or not a pointer, Unmarshal returns
+// an InvalidArgumentError, and if data is not valid it returns a SyntaxError without
+// modifying v.
+//
+// Unmarshal uses the inverse of the encodings that Marshal uses, allocating maps, slices
+// and pointers as necessary, with the following additional rules:
+//
+// MessagePack nil sets a pointer, an interface, a map or a slice to nil, and it has no
+// effect on the other values.
+//
+// To unmarshal into a pointer, Unmarshal allocates a new value if the pointer is nil, and
+// then stores the result in the value pointed to.
+//
+// Integers can be stored in any Go integer type that can represent their value, and in the
+// floating point types. Floats can be stored only in the floating point types.
+// Strings are stored in string, binary payloads in []byte or in a byte array, and the
+// timestamp external type in time.Time. A time.Time accepts also the other formats of the
+// time policies: an integer number of seconds since the Unix epoch, or of milliseconds or
+// nanoseconds with the "time=unixmilli" or "time=unixnano" field option or time policy
+// of the Decoder, a floating point number of seconds, an RFC 3339 string and the external
+// type of the times with their zone, that keeps the location.
+//
+// Arrays are stored in slices, that are replaced with a new slice of the same length,
+// and in Go arrays, discarding the extra elements and setting the missing ones to zero.
+//
+// Maps are stored in Go maps, adding the decoded keys and values to the existing ones,
+// and in structs. The keys of a Go map are decoded with the same rules, so its key type
+// can be an array, a struct or a pointer too, but a key that can't be hashed, like an
+// array stored in an interface, returns an UnmarshalTypeError. When a key is repeated,
+// the last value is stored, unless a Decoder chooses another policy with SetDuplicateKeys.
+//
+// The keys of a map are matched with the struct fields using the same names and tags of
+// Marshal, customkey included. A struct with the "structarray" option is stored from an
+// array instead, assigning the elements to the fields in order. A field with the "string"
+// option is decoded from a string with the decimal form of its value, as Marshal writes it.
+//
+// Keys that don't match any field are ignored, unless a Decoder disallows them with
+// DisallowUnknownFields. A field with the "required" option must be present in the map,
+// or in the array of a struct with the "structarray" option, else a MissingFieldError
+// is returned.
+//
+// To unmarshal into an empty interface, Unmarshal stores one of these values:
+//
+//   nil, for MessagePack nil
+//   bool, for MessagePack boolean
+//   int64, for negative fixint and int
+//   uint64, for positive fixint and uint
+//   float64, for MessagePack float
+//   string, for MessagePack string
+//   []byte, for MessagePack binary
+//   time.Time, for the timestamp external type
+//   []interface{}, for MessagePack array
+//   map[string]interface{}, for MessagePack map with string keys only
+//   map[interface{}]interface{}, for the other MessagePack maps
+//
+// The external types are stored only in the types associated with them using the
+// SetExternalType method of Decoder or a Config, and the other objects return an error
+// when stored in those types.
+//
+// A RawMessage receives a copy of the encoded object, and a Value its tree.
+// Strings and binary payloads are always copied, so v doesn't reference data;
+// use UnmarshalNoCopy to avoid the copy of the binary payloads.
+//
+// If a MessagePack value isn't appropriate for the Go type, or the number doesn't fit
+// in it, Unmarshal stops and returns an UnmarshalTypeError.
+func Unmarshal(data []byte, v interface{}) error {
+	return unmarshal(decode.NewDecoderState(), data, v)
+}
+
+// UnmarshalNoCopy is like Unmarshal, but the byte slices and the RawMessage values stored
+// in v reference data instead of a copy of it, so large binary payloads are not copied.
+// The strings are still copied, because converting bytes into a string without a copy
+// needs the unsafe package.
+//
+// The referenced parts of data must not be modified while v is in use, and they remain
+// in memory as long as v references them, even if only a small part of data is needed.
+// The capacity of every slice is its length, so an append to it allocates a new array
+// instead of overwriting the following bytes of data.
+func UnmarshalNoCopy(data []byte, v interface{}) error {
+	state := decode.NewDecoderState()
+	state.NoCopy = true
+	return unmarshal(state, data, v)
+}
+
+func unmarshal(state *decode.DecoderState, data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return utils.InvalidArgumentError{Desc: "Unmarshal needs a non-nil pointer"}
+	}
+
+	if err := Valid(data); err != nil {
+		return err
+	}
+
+	_, err := state.Decode(data, 0, rv.Elem())
+	return err
+}
@@ -0,0 +1,424 @@
+package sbor
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mustParseDiag returns the MessagePack encoding of the diagnostic notation s.
+func mustParseDiag(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := ParseDiag(s)
+	if err != nil {
+		t.Fatalf("ParseDiag Error: %v", err)
+	}
+	return data
+}
+
+func TestUnmarshal(t *testing.T) {
+	type Inner struct {
+		Name string
+	}
+
+	data := []struct {
+		input    string
+		target   interface{}
+		expected interface{}
+		name     string
+	}{
+		{input: "true", target: new(bool), expected: true, name: "Bool"},
+		{input: "-5", target: new(int8), expected: int8(-5), name: "Int"},
+		{input: "300_u64", target: new(int16), expected: int16(300), name: "Uint into int"},
+		{input: "7", target: new(uint), expected: uint(7), name: "Uint"},
+		{input: "7_i32", target: new(uint8), expected: uint8(7), name: "Positive int into uint"},
+		{input: "-2", target: new(float32), expected: float32(-2), name: "Int into float"},
+		{input: "1.5", target: new(float64), expected: 1.5, name: "Float"},
+		{input: "Infinityf64", target: new(float32), expected: float32(math.Inf(1)), name: "Infinity into float32"},
+		{input: `"hello"`, target: new(string), expected: "hello", name: "String"},
+		{input: "h'00ff'", target: new([]byte), expected: []byte{0x00, 0xFF}, name: "Binary"},
+		{input: "h'0102'", target: new([3]byte), expected: [3]byte{0x01, 0x02, 0x00}, name: "Binary into array"},
+		{input: `ts("2022-03-06T15:20:00Z")`, target: new(time.Time), expected: time.Date(2022, 3, 6, 15, 20, 0, 0, time.UTC), name: "Timestamp"},
+		{input: "1646580000", target: new(time.Time), expected: time.Date(2022, 3, 6, 15, 20, 0, 0, time.UTC), name: "Unix time"},
+		{input: "-1.25", target: new(time.Time), expected: time.Date(1969, 12, 31, 23, 59, 58, 750000000, time.UTC), name: "Unix float time"},
+		{input: `"2022-03-06T16:20:00.5+01:00"`, target: new(time.Time), expected: time.Date(2022, 3, 6, 16, 20, 0, 500000000, time.FixedZone("", 3600)), name: "RFC 3339 time"},
+		{input: "[1, 2, 3]", target: new([]int), expected: []int{1, 2, 3}, name: "Slice"},
+		{input: "[1, 2, 3]", target: new([2]int), expected: [2]int{1, 2}, name: "Shorter array"},
+		{input: "[1]", target: &[2]int{5, 5}, expected: [2]int{1, 0}, name: "Longer array"},
+		{input: `{"a": 1, "b": 2}`, target: new(map[string]uint), expected: map[string]uint{"a": 1, "b": 2}, name: "Map"},
+		{input: "{1: [true], 2: []}", target: new(map[int8][]bool), expected: map[int8][]bool{1: {true}, 2: {}}, name: "Map with int keys"},
+		{input: `{"Name": "x"}`, target: new(*Inner), expected: &Inner{Name: "x"}, name: "Pointer"},
+		{input: "nil", target: &[]int{1}, expected: []int(nil), name: "Nil slice"},
+		{input: "nil", target: func() interface{} { i := 5; return &i }(), expected: 5, name: "Nil into int"},
+		{input: `[-1, 1, 1.5, "s", h'01', nil, false]`, target: new(interface{}),
+			expected: []interface{}{int64(-1), uint64(1), 1.5, "s", []byte{0x01}, nil, false}, name: "Interface array"},
+		{input: `{"a": {"b": 1}}`, target: new(interface{}),
+			expected: map[string]interface{}{"a": map[string]interface{}{"b": uint64(1)}}, name: "Interface map"},
+		{input: `{1: "a", "b": 2}`, target: new(interface{}),
+			expected: map[interface{}]interface{}{uint64(1): "a", "b": uint64(2)}, name: "Interface map with mixed keys"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			if err := Unmarshal(mustParseDiag(t, test.input), test.target); err != nil {
+				t.Fatalf("Unmarshal Error: %v", err)
+			}
+
+			result := reflect.ValueOf(test.target).Elem().Interface()
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Invalid result. Function returned %#v. Expected %#v.", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Struct(t *testing.T) {
+	type Inner struct {
+		Name string
+	}
+
+	type Test struct {
+		Keys     map[string]interface{} `sbor:",setcustomkeys"`
+		Name     string                 `sbor:"name"`
+		Skipped  int                    `sbor:"-"`
+		Hyphen   string                 `sbor:"-,"`
+		Custom   bool                   `sbor:"c,customkey"`
+		Embedded *Inner                 `sbor:",omitempty"`
+		Raw      RawMessage
+		Tree     Value
+		private  int
+	}
+
+	input := Test{
+		Keys:     map[string]interface{}{"c": []int{1, 2}},
+		Name:     "name",
+		Hyphen:   "hyphen",
+		Custom:   true,
+		Embedded: &Inner{Name: "inner"},
+		Raw:      RawMessage{0x92, 0x01, 0x02},
+		Tree:     NewArray(NewString("x")),
+	}
+
+	encoded, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+
+	// The custom keys are needed to match the fields with customkey
+	result := Test{Keys: input.Keys, Skipped: 5, private: 6}
+	if err = Unmarshal(encoded, &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+
+	input.Skipped, input.private = 5, 6
+	if !reflect.DeepEqual(result, input) {
+		t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, input)
+	}
+}
+
+func TestUnmarshal_StructArray(t *testing.T) {
+	type Test struct {
+		A int `sbor:",structarray"`
+		B string
+		C []byte
+	}
+
+	var result Test
+	if err := Unmarshal(mustParseDiag(t, `[1, "b", h'01', "extra"]`), &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+
+	expected := Test{A: 1, B: "b", C: []byte{0x01}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, expected)
+	}
+
+	if err := Unmarshal(mustParseDiag(t, `{"A": 1}`), &result); err == nil {
+		t.Error("Error was expected.")
+	}
+}
+
+func TestUnmarshal_Error(t *testing.T) {
+	data := []struct {
+		input  string
+		target interface{}
+		name   string
+	}{
+		{input: "256", target: new(uint8), name: "Overflow"},
+		{input: "-1", target: new(uint), name: "Negative into uint"},
+		{input: "1.5", target: new(int), name: "Float into int"},
+		{input: "1e300", target: new(float32), name: "Float32 overflow"},
+		{input: `"a"`, target: new([]byte), name: "String into bytes"},
+		{input: "h'01'", target: new(string), name: "Binary into string"},
+		{input: "ext(5, h'01')", target: new(interface{}), name: "Unknown ext"},
+		{input: "[[1]]", target: new([]int), name: "Nested"},
+		{input: "18446744073709551615", target: new(time.Time), name: "Unix time overflow"},
+		{input: "NaN", target: new(time.Time), name: "Unix float time NaN"},
+		{input: `"2022-03-06"`, target: new(time.Time), name: "Invalid RFC 3339 time"},
+		{input: "true", target: new(time.Time), name: "Boolean into time"},
+		{input: "{[1]: 1}", target: new(interface{}), name: "Unhashable key"},
+		{input: "{[1]: 1}", target: new(map[interface{}]int), name: "Unhashable map key"},
+		{input: "1", target: new(error), name: "Non-empty interface"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			err := Unmarshal(mustParseDiag(t, test.input), test.target)
+			var typeError UnmarshalTypeError
+			if !errors.As(err, &typeError) {
+				t.Errorf("UnmarshalTypeError was expected. Function returned %v.", err)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_InvalidArgument(t *testing.T) {
+	var i int
+	data := mustParseDiag(t, "1")
+
+	if err := Unmarshal(data, i); err == nil {
+		t.Error("Error was expected with a non pointer value.")
+	}
+
+	if err := Unmarshal(data, nil); err == nil {
+		t.Error("Error was expected with nil.")
+	}
+
+	var syntax SyntaxError
+	if err := Unmarshal(append(data, 0x01), &i); !errors.As(err, &syntax) || i != 0 {
+		t.Errorf("SyntaxError was expected without modifying the value. Function returned %v, %d.", err, i)
+	}
+}
+
+func TestUnmarshal_TagNames(t *testing.T) {
+	type Test struct {
+		A string `json:"a"`
+		B string `json:"b,omitempty" msgpack:"bb"`
+		C string `sbor:"c" json:"-"`
+		D string `json:"-"`
+	}
+
+	input := Test{A: "a", B: "b", C: "c", D: "d"}
+
+	var b bytes.Buffer
+	e := NewEncoder(&b)
+	e.SetTagName("sbor", "msgpack", "json")
+	if err := e.Encode(input); err != nil {
+		t.Fatalf("Encoder Error: %v", err)
+	}
+
+	expected := mustParseDiag(t, `{"a": "a", "bb": "b", "c": "c"}`)
+	if !bytes.Equal(b.Bytes(), expected) {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", b.Bytes(), expected)
+	}
+
+	var result Test
+	d := NewDecoder(&b)
+	d.SetTagName("sbor", "msgpack", "json")
+	if err := d.Decode(&result); err != nil {
+		t.Fatalf("Decoder Error: %v", err)
+	}
+
+	input.D = ""
+	if result != input {
+		t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, input)
+	}
+}
+
+func TestUnmarshal_String(t *testing.T) {
+	type Test struct {
+		ID      int64    `sbor:"id,string"`
+		Count   *uint16  `sbor:"count,string"`
+		Enabled bool     `sbor:"enabled,string"`
+		Ratio   float64  `sbor:"ratio,string"`
+		Items   []string `sbor:"items,string"`
+	}
+
+	count := uint16(7)
+	input := Test{ID: -1 << 62, Count: &count, Enabled: true, Ratio: 0.25, Items: []string{"a"}}
+
+	encoded, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+
+	expected := mustParseDiag(t, `{"id": "-4611686018427387904", "count": "7", "enabled": "true", "ratio": "0.25", "items": ["a"]}`)
+	if !bytes.Equal(encoded, expected) {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", encoded, expected)
+	}
+
+	var result Test
+	if err = Unmarshal(encoded, &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+	if !reflect.DeepEqual(result, input) {
+		t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, input)
+	}
+
+	// The number must be in a string
+	for _, invalid := range []string{`{"id": 1}`, `{"id": "1.5"}`, `{"count": "70000"}`, `{"enabled": "yes"}`} {
+		var typeError UnmarshalTypeError
+		if err = Unmarshal(mustParseDiag(t, invalid), &result); !errors.As(err, &typeError) {
+			t.Errorf("UnmarshalTypeError was expected for %s. Function returned %v.", invalid, err)
+		}
+	}
+}
+
+func TestUnmarshal_CompositeKeys(t *testing.T) {
+	type Point struct {
+		X, Y int
+	}
+
+	type Test struct {
+		Arrays   map[[2]int]string
+		Structs  map[Point]bool
+		Pointers map[*Point]int
+	}
+
+	input := Test{
+		Arrays:   map[[2]int]string{{1, 2}: "a", {0, 5}: "b"},
+		Structs:  map[Point]bool{{X: 1}: true, {Y: 1}: false},
+		Pointers: map[*Point]int{{X: 3}: 3},
+	}
+
+	encoded, err := Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+
+	// The keys are sorted by their encoding
+	expected := mustParseDiag(t, `{"Arrays": {[0, 5]: "b", [1, 2]: "a"}, "Structs": {{"X": 0, "Y": 1}: false, {"X": 1, "Y": 0}: true}, "Pointers": {{"X": 3, "Y": 0}: 3}}`)
+	if !bytes.Equal(encoded, expected) {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", encoded, expected)
+	}
+
+	var result Test
+	if err = Unmarshal(encoded, &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+	if !reflect.DeepEqual(result.Arrays, input.Arrays) || !reflect.DeepEqual(result.Structs, input.Structs) {
+		t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, input)
+	}
+	for key, value := range result.Pointers {
+		if *key != (Point{X: 3}) || value != 3 || len(result.Pointers) != 1 {
+			t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result.Pointers, input.Pointers)
+		}
+	}
+
+	// The interfaces in a key must contain hashable values
+	var unhashable map[[1]interface{}]int
+	var typeError UnmarshalTypeError
+	if err = Unmarshal(mustParseDiag(t, "{[[1]]: 1}"), &unhashable); !errors.As(err, &typeError) || typeError.Offset != 1 {
+		t.Errorf("UnmarshalTypeError was expected at offset 1. Function returned %v.", err)
+	}
+}
+
+func TestUnmarshal_Required(t *testing.T) {
+	type Test struct {
+		Keys   map[string]interface{} `sbor:",setcustomkeys"`
+		Name   string                 `sbor:"name,required"`
+		Custom *bool                  `sbor:"c,customkey,required"`
+		Other  int                    `sbor:"other"`
+	}
+
+	type Array struct {
+		A int    `sbor:",structarray,required"`
+		B string `sbor:",required"`
+		C []byte
+	}
+
+	data := []struct {
+		input  string
+		target interface{}
+		field  string
+		name   string
+	}{
+		{input: `{"name": "n", 1: nil}`, target: &Test{Keys: map[string]interface{}{"c": 1}}, name: "all present"},
+		{input: `{"name": "n", "other": 1}`, target: &Test{Keys: map[string]interface{}{"c": 1}}, field: "c", name: "missing custom key"},
+		{input: `{1: true, "other": 1}`, target: &Test{Keys: map[string]interface{}{"c": 1}}, field: "name", name: "missing name"},
+		{input: `[1, "b"]`, target: &Array{}, name: "array without optional element"},
+		{input: `[1]`, target: &Array{}, field: "B", name: "shorter array"},
+		{input: `[]`, target: &Array{}, field: "A", name: "empty array"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			err := Unmarshal(mustParseDiag(t, test.input), test.target)
+			if test.field == "" {
+				if err != nil {
+					t.Errorf("Unmarshal Error: %v", err)
+				}
+				return
+			}
+
+			var missing MissingFieldError
+			if !errors.As(err, &missing) || missing.Field != test.field || missing.Offset != 0 {
+				t.Errorf("MissingFieldError was expected for the field %s. Function returned %v.", test.field, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_TimeOptions(t *testing.T) {
+	type Test struct {
+		Seconds time.Time
+		Milli   []time.Time `sbor:",time=unixmilli"`
+		Nano    *time.Time  `sbor:",time=unixnano"`
+	}
+
+	var result Test
+	if err := Unmarshal(mustParseDiag(t, `{"Seconds": 1, "Milli": [1500], "Nano": 1000000001}`), &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+
+	nano := time.Unix(1, 1).UTC()
+	expected := Test{Seconds: time.Unix(1, 0).UTC(), Milli: []time.Time{time.Unix(1, 500000000).UTC()}, Nano: &nano}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, expected)
+	}
+
+	var invalid struct {
+		A time.Time `sbor:",time=iso"`
+	}
+	if err := Unmarshal(mustParseDiag(t, `{"A": 1}`), &invalid); err == nil {
+		t.Error("Error was expected with an unknown time option.")
+	}
+}
+
+func TestUnmarshalNoCopy(t *testing.T) {
+	type Test struct {
+		Payload []byte
+		Raw     RawMessage
+		Any     interface{}
+		Text    string
+	}
+
+	data := mustParseDiag(t, `{"Payload": h'0102', "Raw": [1], "Any": h'03', "Text": "t"}`)
+
+	var result Test
+	if err := UnmarshalNoCopy(data, &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+
+	expected := Test{Payload: []byte{0x01, 0x02}, Raw: RawMessage{0x91, 0x01}, Any: []byte{0x03}, Text: "t"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, expected)
+	}
+	if cap(result.Payload) != len(result.Payload) {
+		t.Errorf("Invalid capacity. Function returned %d. Expected %d.", cap(result.Payload), len(result.Payload))
+	}
+
+	// The slices reference data
+	for i := range data {
+		data[i] = 0
+	}
+	if !bytes.Equal(result.Payload, []byte{0, 0}) || !bytes.Equal(result.Raw, []byte{0, 0}) || !bytes.Equal(result.Any.([]byte), []byte{0}) {
+		t.Errorf("The slices don't reference the input data. Function returned %+v.", result)
+	}
+	if result.Text != "t" {
+		t.Errorf("The string references the input data. Function returned %q.", result.Text)
+	}
+}
@@ -0,0 +1,518 @@
+package sbor
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDiag returns the MessagePack encoding of the objects written in s using the
+// diagnostic notation, the same one written by Dump with the Compact option.
+// Objects separated by spaces or new lines are concatenated.
+//
+// Every object is encoded with the smallest format for its value, unless its
+// width is forced with a suffix (non-negative integers use the uint formats):
+//
+//   nil, true, false
+//   1, -8, 1_u16, -8_i32, 5_i8       uint and int, with _u8 ... _u64 and _i8 ... _i64
+//   3.5, 1e-3, 3.5f32, 0.1f64, NaN   float, with f32 and f64
+//   NaN(h'7fe31702')                 NaN with the given bits, float32 or float64 by payload size
+//   "text", "text"_8                 str, with _8, _16 and _32
+//   h'ccff', h''_16                  bin, with _8, _16 and _32
+//   ext(16, h'74657374')_8           ext with its type, with _8, _16 and _32
+//   ts("2022-03-06T15:20:00Z")       timestamp (ext type -1), written in RFC 3339
+//   [1, 2], [1]_16                   array, with _16 and _32
+//   {"a": 1, 2: [3]}, {}_32          map, with _16 and _32
+//
+// Strings use the Go syntax for escape sequences. It's useful to write readable test
+// fixtures:
+//
+//   expected, err := sbor.ParseDiag(`{"id": 1_u16, "tags": ["a", "b"]}`)
+func ParseDiag(s string) ([]byte, error) {
+	p := diagParser{s: s}
+
+	p.skipSpaces()
+	if p.pos == len(s) {
+		return nil, utils.DiagSyntaxError{Offset: p.pos, Desc: "unexpected end of input"}
+	}
+
+	var b []byte
+	var err error
+	for p.pos < len(s) {
+		if b, err = p.value(b, 0); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+	}
+
+	return b, nil
+}
+
+type diagParser struct {
+	s   string
+	pos int
+}
+
+func (p *diagParser) skipSpaces() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// next reports whether the next character, after the spaces, is c.
+func (p *diagParser) next(c byte) bool {
+	p.skipSpaces()
+	return p.pos < len(p.s) && p.s[p.pos] == c
+}
+
+// expect consumes the character c, after the spaces.
+func (p *diagParser) expect(c byte) error {
+	if !p.next(c) {
+		return utils.DiagSyntaxError{Offset: p.pos, Desc: "expected " + strconv.QuoteRune(rune(c))}
+	}
+	p.pos++
+	return nil
+}
+
+// word consumes a sequence of letters, digits and the characters "_.+-".
+func (p *diagParser) word() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("_.+-", c) >= 0) {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// value appends the encoding of the next object to b.
+func (p *diagParser) value(b []byte, depth int) ([]byte, error) {
+	p.skipSpaces()
+	if p.pos == len(p.s) {
+		return nil, utils.DiagSyntaxError{Offset: p.pos, Desc: "unexpected end of input"}
+	}
+
+	start := p.pos
+	switch c := p.s[p.pos]; {
+	case c == '[' || c == '{':
+		if depth >= decode.MaxDepth {
+			return nil, utils.DiagSyntaxError{Offset: start, Desc: "exceeded max nesting depth"}
+		}
+		return p.container(b, depth)
+
+	case c == '"':
+		s, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		return p.withLength(b, decode.String, []byte(s), 0)
+
+	case strings.HasPrefix(p.s[p.pos:], "h'"):
+		payload, err := p.hex()
+		if err != nil {
+			return nil, err
+		}
+		return p.withLength(b, decode.Binary, payload, 0)
+	}
+
+	word := p.word()
+	switch word {
+	case "":
+		return nil, utils.DiagSyntaxError{Offset: start, Desc: "unexpected character " + strconv.QuoteRune(rune(p.s[start]))}
+	case "nil":
+		return append(b, types.NilCode), nil
+	case "true":
+		return append(b, types.True), nil
+	case "false":
+		return append(b, types.False), nil
+	case "ext", "ts":
+		if p.next('(') {
+			return p.ext(b, word == "ts")
+		}
+	case "NaN":
+		if p.next('(') {
+			return p.nan(b, start)
+		}
+	}
+
+	return p.number(b, start, word)
+}
+
+// quoted consumes a quoted string and returns its value.
+func (p *diagParser) quoted() (string, error) {
+	start := p.pos
+	for p.pos++; p.pos < len(p.s) && p.s[p.pos] != '"'; p.pos++ {
+		if p.s[p.pos] == '\\' {
+			p.pos++
+		}
+	}
+	if p.pos >= len(p.s) {
+		return "", utils.DiagSyntaxError{Offset: start, Desc: "unterminated string"}
+	}
+	p.pos++
+
+	s, err := strconv.Unquote(p.s[start:p.pos])
+	if err != nil {
+		return "", utils.DiagSyntaxError{Offset: start, Desc: "invalid string"}
+	}
+	return s, nil
+}
+
+// hex consumes a binary payload written as h'...' and returns its value.
+func (p *diagParser) hex() ([]byte, error) {
+	start := p.pos
+	end := strings.IndexByte(p.s[start+2:], '\'')
+	if end < 0 {
+		return nil, utils.DiagSyntaxError{Offset: start, Desc: "unterminated binary"}
+	}
+	p.pos = start + 2 + end + 1
+
+	payload, err := hex.DecodeString(p.s[start+2 : p.pos-1])
+	if err != nil {
+		return nil, utils.DiagSyntaxError{Offset: start, Desc: "invalid hexadecimal binary"}
+	}
+	return payload, nil
+}
+
+// ext appends the encoding of ext(type, h'...') or ts("...") to b.
+func (p *diagParser) ext(b []byte, timestamp bool) ([]byte, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	start := p.pos
+	var extType int8
+	var payload []byte
+
+	if timestamp {
+		if !p.next('"') {
+			return nil, utils.DiagSyntaxError{Offset: p.pos, Desc: "expected a quoted timestamp"}
+		}
+		s, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, utils.DiagSyntaxError{Offset: start, Desc: "invalid RFC 3339 timestamp"}
+		}
+		extType, payload = encode.Timestamp, encode.NewTimestamp(t).Data
+	} else {
+		t, err := strconv.ParseInt(p.word(), 10, 8)
+		if err != nil {
+			return nil, utils.DiagSyntaxError{Offset: start, Desc: "invalid ext type"}
+		}
+		if err = p.expect(','); err != nil {
+			return nil, err
+		}
+		if p.skipSpaces(); !strings.HasPrefix(p.s[p.pos:], "h'") {
+			return nil, utils.DiagSyntaxError{Offset: p.pos, Desc: "expected a binary payload"}
+		}
+		if payload, err = p.hex(); err != nil {
+			return nil, err
+		}
+		extType = int8(t)
+	}
+
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return p.withLength(b, decode.Ext, payload, extType)
+}
+
+// nan appends the encoding of NaN(h'...') to b, a float32 or a float64 with the given bits.
+func (p *diagParser) nan(b []byte, start int) ([]byte, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	if p.skipSpaces(); !strings.HasPrefix(p.s[p.pos:], "h'") {
+		return nil, utils.DiagSyntaxError{Offset: p.pos, Desc: "expected a binary payload"}
+	}
+	payload, err := p.hex()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect(')'); err != nil {
+		return nil, err
+	}
+
+	invalid := utils.DiagSyntaxError{Offset: start, Desc: "invalid NaN payload"}
+	switch len(payload) {
+	case 4:
+		if !math.IsNaN(float64(math.Float32frombits(binary.BigEndian.Uint32(payload)))) {
+			return nil, invalid
+		}
+		return append(append(b, types.Float32), payload...), nil
+	case 8:
+		if !math.IsNaN(math.Float64frombits(binary.BigEndian.Uint64(payload))) {
+			return nil, invalid
+		}
+		return append(append(b, types.Float64), payload...), nil
+	default:
+		return nil, invalid
+	}
+}
+
+// container appends the encoding of an array or a map to b.
+func (p *diagParser) container(b []byte, depth int) ([]byte, error) {
+	kind, closing := decode.Array, byte(']')
+	if p.s[p.pos] == '{' {
+		kind, closing = decode.Map, '}'
+	}
+	p.pos++
+
+	var body []byte
+	var length int
+	var err error
+
+	if p.next(closing) {
+		p.pos++
+	} else {
+		for {
+			if body, err = p.value(body, depth+1); err != nil {
+				return nil, err
+			}
+			if kind == decode.Map {
+				if err = p.expect(':'); err != nil {
+					return nil, err
+				}
+				if body, err = p.value(body, depth+1); err != nil {
+					return nil, err
+				}
+			}
+			length++
+
+			if !p.next(',') {
+				break
+			}
+			p.pos++
+		}
+
+		if err = p.expect(closing); err != nil {
+			return nil, err
+		}
+	}
+
+	start := p.pos
+	width, err := p.width()
+	if err != nil {
+		return nil, err
+	}
+
+	var header []byte
+	switch {
+	case width == 0 && kind == decode.Map:
+		header, err = types.MapHeader(length)
+	case width == 0:
+		header, err = types.ArrayHeader(length)
+	case kind == decode.Map:
+		header, err = diagHeader(types.Map16, false, length, width, start)
+	default:
+		header, err = diagHeader(types.Array16, false, length, width, start)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b, header...)
+	return append(b, body...), nil
+}
+
+// withLength appends to b the encoding of the str, bin or ext object with the given payload,
+// consuming its optional width suffix.
+func (p *diagParser) withLength(b []byte, kind decode.Kind, payload []byte, extType int8) ([]byte, error) {
+	start := p.pos
+	width, err := p.width()
+	if err != nil {
+		return nil, err
+	}
+
+	if width == 0 {
+		switch kind {
+		case decode.String:
+			return appendType(b, types.String(payload))
+		case decode.Binary:
+			return appendType(b, types.Binary(payload))
+		default:
+			return appendType(b, types.External{Type: byte(extType), Data: payload})
+		}
+	}
+
+	code16 := byte(types.Ext16)
+	switch kind {
+	case decode.String:
+		code16 = types.Str16
+	case decode.Binary:
+		code16 = types.Bin16
+	}
+	header, err := diagHeader(code16, true, len(payload), width, start)
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b, header...)
+	if kind == decode.Ext {
+		b = append(b, byte(extType))
+	}
+	return append(b, payload...), nil
+}
+
+// width consumes the optional _8, _16 or _32 suffix and returns its value, or 0 if it's missing.
+func (p *diagParser) width() (int, error) {
+	if p.pos == len(p.s) || p.s[p.pos] != '_' {
+		return 0, nil
+	}
+
+	start := p.pos
+	p.pos++
+	switch suffix := p.word(); suffix {
+	case "8", "16", "32":
+		width, _ := strconv.Atoi(suffix)
+		return width, nil
+	default:
+		return 0, utils.DiagSyntaxError{Offset: start, Desc: "invalid width _" + suffix}
+	}
+}
+
+// diagHeader returns the header for length elements with a length field of width bits.
+// code16 is the code of the format with a 16 bit length field: the 8 bit one precedes it,
+// if has8 is true, and the 32 bit one follows it.
+func diagHeader(code16 byte, has8 bool, length int, width int, offset int) ([]byte, error) {
+	var header []byte
+
+	switch {
+	case width == 8 && has8 && length <= math.MaxUint8:
+		header = []byte{code16 - 1, byte(length)}
+	case width == 16 && length <= math.MaxUint16:
+		header = []byte{code16, 0, 0}
+		binary.BigEndian.PutUint16(header[1:], uint16(length))
+	case width == 32 && length <= math.MaxUint32:
+		header = []byte{code16 + 1, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(header[1:], uint32(length))
+	case width == 8 && !has8:
+		return nil, utils.DiagSyntaxError{Offset: offset, Desc: "invalid width _8 for " + decode.FormatName(code16)}
+	default:
+		return nil, utils.DiagSyntaxError{Offset: offset, Desc: "length " + strconv.Itoa(length) + " exceeds width _" + strconv.Itoa(width)}
+	}
+
+	return header, nil
+}
+
+// number appends to b the encoding of the integer or float written in word.
+func (p *diagParser) number(b []byte, start int, word string) ([]byte, error) {
+	literal, suffix := word, ""
+	if i := strings.LastIndexByte(word, '_'); i >= 0 {
+		literal, suffix = word[:i], word[i:]
+	} else if strings.HasSuffix(word, "f32") || strings.HasSuffix(word, "f64") {
+		literal, suffix = word[:len(word)-3], word[len(word)-3:]
+	}
+
+	invalid := utils.DiagSyntaxError{Offset: start, Desc: "invalid value " + strconv.Quote(word)}
+
+	isSpecial := literal == "NaN" || literal == "Infinity" || literal == "-Infinity"
+	if !isSpecial && strings.Trim(literal, "0123456789.eE+-") != "" {
+		return nil, invalid
+	}
+
+	if isSpecial || suffix == "f32" || suffix == "f64" || strings.ContainsAny(literal, ".eE") {
+		bitSize := 64
+		if suffix == "f32" {
+			bitSize = 32
+		}
+
+		f, err := parseFloat(literal, bitSize)
+		if err != nil {
+			return nil, invalid
+		}
+
+		switch suffix {
+		case "":
+			return AppendFloat(b, f), nil
+		case "f32":
+			return appendFixed(b, types.Float32, uint64(math.Float32bits(float32(f))), 4), nil
+		case "f64":
+			return appendFixed(b, types.Float64, math.Float64bits(f), 8), nil
+		default:
+			return nil, invalid
+		}
+	}
+
+	if suffix == "" {
+		if strings.HasPrefix(literal, "-") {
+			i, err := strconv.ParseInt(literal, 10, 64)
+			if err != nil {
+				return nil, invalid
+			}
+			return AppendInt(b, i), nil
+		}
+
+		u, err := strconv.ParseUint(literal, 10, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		return AppendUint(b, u), nil
+	}
+
+	// Integer suffix, from _u8 to _i64
+	if len(suffix) < 3 {
+		return nil, invalid
+	}
+	bitSize, err := strconv.Atoi(suffix[2:])
+	if err != nil || (bitSize != 8 && bitSize != 16 && bitSize != 32 && bitSize != 64) {
+		return nil, invalid
+	}
+	size := bitSize / 8
+	log := byte(bits.TrailingZeros(uint(size)))
+
+	switch suffix[1] {
+	case 'u':
+		u, err := strconv.ParseUint(literal, 10, bitSize)
+		if err != nil {
+			return nil, invalid
+		}
+		return appendFixed(b, types.Uint8+log, u, size), nil
+	case 'i':
+		i, err := strconv.ParseInt(literal, 10, bitSize)
+		if err != nil {
+			return nil, invalid
+		}
+		return appendFixed(b, types.Int8+log, uint64(i), size), nil
+	default:
+		return nil, invalid
+	}
+}
+
+// appendFixed appends to b the code followed by the size least significant bytes of v, in big-endian order.
+func appendFixed(b []byte, code byte, v uint64, size int) []byte {
+	b = append(b, code)
+	for i := size - 1; i >= 0; i-- {
+		b = append(b, byte(v>>(8*i)))
+	}
+	return b
+}
+
+// parseFloat parses a float written in the diagnostic notation, where the
+// special values are NaN, Infinity and -Infinity.
+func parseFloat(s string, bitSize int) (float64, error) {
+	switch s {
+	case "NaN":
+		return math.NaN(), nil
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	default:
+		return strconv.ParseFloat(s, bitSize)
+	}
+}
@@ -0,0 +1,112 @@
+package sbor
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDiag(t *testing.T) {
+	data := []struct {
+		input    string
+		expected []byte
+		name     string
+	}{
+		{input: `{"a": [1, -8, 3.5f32, h'ccff', ext(16, h'74657374'), nil]}`, expected: []byte{0x81, 0xA1, 0x61, 0x96, 0x01, 0xF8, 0xCA, 0x40, 0x60, 0x00, 0x00, 0xC4, 0x02, 0xCC, 0xFF, 0xD6, 0x10, 0x74, 0x65, 0x73, 0x74, 0xC0}, name: "Map"},
+		{input: "1_u16", expected: []byte{0xCD, 0x00, 0x01}, name: "Uint16"},
+		{input: "-1_i64", expected: []byte{0xD3, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, name: "Int64"},
+		{input: "300", expected: []byte{0xCD, 0x01, 0x2C}, name: "Uint"},
+		{input: "-300", expected: []byte{0xD1, 0xFE, 0xD4}, name: "Int"},
+		{input: "0.1", expected: []byte{0xCB, 0x3F, 0xB9, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A}, name: "Float"},
+		{input: "-Infinityf64", expected: []byte{0xCB, 0xFF, 0xF0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, name: "Infinity"},
+		{input: "NaN(h'7fe31702')", expected: []byte{0xCA, 0x7F, 0xE3, 0x17, 0x02}, name: "NaN float32"},
+		{input: "NaN(h'fff8000000000012')", expected: []byte{0xCB, 0xFF, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x12}, name: "NaN float64"},
+		{input: `"é"_16`, expected: []byte{0xDA, 0x00, 0x02, 0xC3, 0xA9}, name: "Str16"},
+		{input: "h''", expected: []byte{0xC4, 0x00}, name: "Empty bin"},
+		{input: "ext(-128, h'01')_32", expected: []byte{0xC9, 0x00, 0x00, 0x00, 0x01, 0x80, 0x01}, name: "Ext32"},
+		{input: `ts("2022-03-06T15:20:00Z")`, expected: []byte{0xD6, 0xFF, 0x62, 0x24, 0xD1, 0x20}, name: "Timestamp"},
+		{input: "[ ]_16", expected: []byte{0xDC, 0x00, 0x00}, name: "Array16"},
+		{input: "{1: {}_32}", expected: []byte{0x81, 0x01, 0xDF, 0x00, 0x00, 0x00, 0x00}, name: "Map32"},
+		{input: "\ttrue\n false\n", expected: []byte{0xC3, 0xC2}, name: "Stream"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ParseDiag(test.input)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if !bytes.Equal(result, test.expected) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseDiag_Dump(t *testing.T) {
+	value := map[string]interface{}{
+		"float32": float32(0.1),
+		"float64": 0.1,
+		"ints":    []interface{}{int8(5), int64(-40000), uint32(7)},
+		"time":    time.Unix(1646580000, 12345),
+		"text":    strings.Repeat("x", 40),
+		"binary":  []byte{0x00, 0xFF},
+	}
+	data, err := Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+	data = append(data, dumpTestData...)
+	data = AppendTime(data, time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC))
+	data = AppendTime(data, time.Date(-1, 12, 31, 23, 59, 59, 5, time.UTC))
+	data = append(data, 0xCA, 0x7F, 0xE3, 0x17, 0x02, 0xCA, 0xFF, 0xC0, 0x00, 0x00)
+	data = append(data, 0xCB, 0x7F, 0xF0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01)
+
+	var b bytes.Buffer
+	if err = Dump(&b, data, DumpOptions{Compact: true}); err != nil {
+		t.Fatalf("Dump Error: %v", err)
+	}
+
+	result, err := ParseDiag(b.String())
+	if err != nil {
+		t.Fatalf("ParseDiag Error: %v", err)
+	}
+	if !bytes.Equal(result, data) {
+		t.Errorf("Invalid round trip of %s. Function returned %v. Expected %v.", b.String(), result, data)
+	}
+}
+
+func TestParseDiag_Error(t *testing.T) {
+	data := []struct {
+		input  string
+		offset int
+	}{
+		{input: "", offset: 0},
+		{input: "[1, 2", offset: 5},
+		{input: `{"a" 1}`, offset: 5},
+		{input: "256_u8", offset: 0},
+		{input: "-1_u16", offset: 0},
+		{input: "1_x", offset: 0},
+		{input: "0x10", offset: 0},
+		{input: "[1]_8", offset: 3},
+		{input: `"abc`, offset: 0},
+		{input: "h'0'", offset: 0},
+		{input: "ext(200, h'00')", offset: 4},
+		{input: `ts("yesterday")`, offset: 3},
+		{input: "NaN(h'3f800000')", offset: 0},
+		{input: "NaN(h'7fc000')", offset: 0},
+		{input: "@", offset: 0},
+	}
+
+	for _, test := range data {
+		_, err := ParseDiag(test.input)
+
+		var syntax DiagSyntaxError
+		if !errors.As(err, &syntax) || syntax.Offset != test.offset {
+			t.Errorf("Invalid error for %s. Function returned %v. Expected offset %d.", test.input, err, test.offset)
+		}
+	}
+}
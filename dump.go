@@ -0,0 +1,382 @@
+package sbor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DumpOptions configures the output of Dump.
+type DumpOptions struct {
+	// Indent is repeated once for every nesting level. Two spaces are used if it's empty.
+	Indent string
+
+	// Compact specifies that every object is written on a single line, using the
+	// diagnostic notation instead of the annotated view.
+	Compact bool
+}
+
+// Dump writes a human-readable view of the MessagePack objects concatenated in data to w.
+//
+// By default every object is written on its own line, containing the offset of its
+// first byte in hexadecimal, the name of its format (fixmap, str8, ext8 and so on),
+// its length and its value. The elements of arrays and maps are indented below them,
+// and the values of maps are indented below their keys:
+//
+//   0000  fixmap len=1
+//   0001    fixstr len=1 "a"
+//   0003      fixarray len=2
+//   0004        positive fixint 1
+//   0005        fixext4 len=4 type=-1 2022-03-06T15:20:00Z
+//
+// The external type -1 is written as an RFC 3339 timestamp.
+//
+// With the Compact option every top-level object is written on a single line, using a
+// diagnostic notation similar to JSON:
+//
+//   {"a": [1, -8, 3.5, h'ccff', ext(16, h'74657374'), ts("2022-03-06T15:20:00Z"), nil]}
+//
+// Binary payloads are written in hexadecimal as h'...', and floats always have a decimal
+// point or an exponent. A NaN with other bits than the one written by ParseDiag for NaN
+// is written with its payload, as NaN(h'7fe31702'). When an object doesn't use the smallest format for its value,
+// the format is written after it: 1_u16 is a uint16, 1_i8 an int8, 3.5f64 a float64,
+// and "a"_8, h'00'_16, [1]_32 and ext(1, h'00')_8 have a length field of 8, 16 or 32 bits.
+// Timestamps with a year outside 0 ... 9999 are written as ext(-1, h'...'), since RFC 3339
+// can't represent them. ParseDiag encodes this notation back to the same bytes.
+//
+// If data isn't valid, the objects are written up to the invalid byte,
+// and a SyntaxError is returned.
+func Dump(w io.Writer, data []byte, opts DumpOptions) error {
+	d := dumper{
+		w:      bufio.NewWriter(w),
+		data:   data,
+		indent: opts.Indent,
+		width:  len(strconv.FormatInt(int64(len(data)), 16)),
+	}
+	if d.indent == "" {
+		d.indent = "  "
+	}
+	if d.width < 4 {
+		d.width = 4
+	}
+
+	var err error
+	for offset := 0; offset < len(data) && err == nil; {
+		if opts.Compact {
+			offset, err = d.diag(offset, 0)
+			d.w.WriteByte('\n')
+		} else {
+			offset, err = d.annotate(offset, 0)
+		}
+	}
+
+	if flushErr := d.w.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+type dumper struct {
+	w      *bufio.Writer
+	data   []byte
+	indent string
+	width  int // Number of hexadecimal digits of the offsets
+}
+
+// header reads the header of the object that starts at data[offset],
+// checking that its payload is complete.
+func (d *dumper) header(offset int, depth int) (decode.Header, int, error) {
+	h, err := decode.ReadHeader(d.data, offset)
+	if err != nil {
+		return h, offset, err
+	}
+
+	end := offset + h.Size + h.Payload()
+	if end > len(d.data) || end < offset {
+		return h, offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + decode.FormatName(h.Code) + " payload"}
+	}
+	if h.Elements() > 0 && depth >= decode.MaxDepth {
+		return h, offset, utils.SyntaxError{Offset: offset, Desc: "exceeded max nesting depth"}
+	}
+	return h, end, nil
+}
+
+// annotate writes the annotated view of the object that starts at data[offset],
+// and returns the offset of the first byte after it.
+func (d *dumper) annotate(offset int, depth int) (int, error) {
+	h, end, err := d.header(offset, depth)
+	if err != nil {
+		return offset, err
+	}
+
+	fmt.Fprintf(d.w, "%0*x  %s%s", d.width, offset, strings.Repeat(d.indent, depth), decode.FormatName(h.Code))
+	switch h.Kind {
+	case decode.String, decode.Binary, decode.Array, decode.Map, decode.Ext:
+		fmt.Fprintf(d.w, " len=%d", h.Length)
+	}
+
+	payload := d.data[offset+h.Size : end]
+	switch h.Kind {
+	case decode.Nil, decode.Boolean, decode.Array, decode.Map:
+		// The format name is enough
+		d.w.WriteByte('\n')
+	case decode.Ext:
+		fmt.Fprintf(d.w, " type=%d ", h.ExtType)
+		if t, ok := timestamp(h, payload); ok {
+			d.w.WriteString(t.Format(time.RFC3339Nano))
+		} else {
+			writeHex(d.w, payload)
+		}
+		d.w.WriteByte('\n')
+	default:
+		d.w.WriteByte(' ')
+		d.scalar(offset, h)
+		d.w.WriteByte('\n')
+	}
+
+	for i := 0; i < h.Length && h.Elements() > 0; i++ {
+		if h.Kind == decode.Map {
+			if end, err = d.annotate(end, depth+1); err != nil {
+				return end, err
+			}
+			if end, err = d.annotate(end, depth+2); err != nil {
+				return end, err
+			}
+		} else if end, err = d.annotate(end, depth+1); err != nil {
+			return end, err
+		}
+	}
+
+	return end, nil
+}
+
+// diag writes the diagnostic notation of the object that starts at data[offset],
+// and returns the offset of the first byte after it.
+func (d *dumper) diag(offset int, depth int) (int, error) {
+	h, end, err := d.header(offset, depth)
+	if err != nil {
+		return offset, err
+	}
+
+	payload := d.data[offset+h.Size : end]
+	switch h.Kind {
+	case decode.Array, decode.Map:
+		opening, closing := byte('['), byte(']')
+		if h.Kind == decode.Map {
+			opening, closing = '{', '}'
+		}
+
+		d.w.WriteByte(opening)
+		for i := 0; i < h.Elements(); i++ {
+			switch {
+			case i == 0:
+			case h.Kind == decode.Map && i%2 == 1:
+				d.w.WriteString(": ")
+			default:
+				d.w.WriteString(", ")
+			}
+			if end, err = d.diag(end, depth+1); err != nil {
+				return end, err
+			}
+		}
+		d.w.WriteByte(closing)
+
+	case decode.Ext:
+		if t, ok := rfc3339Timestamp(h, payload); ok {
+			fmt.Fprintf(d.w, "ts(%q)", t.Format(time.RFC3339Nano))
+		} else {
+			fmt.Fprintf(d.w, "ext(%d, ", h.ExtType)
+			writeHex(d.w, payload)
+			d.w.WriteByte(')')
+		}
+
+	default:
+		d.scalar(offset, h)
+	}
+
+	if !canonical(d.data, offset, h) {
+		d.w.WriteString(formatSuffix(h.Code))
+	}
+	return end, nil
+}
+
+// scalar writes the value of the object with header h that starts at data[offset].
+// The object must not be an array, a map or an external type.
+func (d *dumper) scalar(offset int, h decode.Header) {
+	payload := d.data[offset+h.Size : offset+h.Size+h.Payload()]
+
+	switch h.Kind {
+	case decode.Nil:
+		d.w.WriteString("nil")
+	case decode.Boolean:
+		d.w.WriteString(strconv.FormatBool(h.Code == types.True))
+	case decode.Int:
+		d.w.WriteString(strconv.FormatInt(decode.ReadInt(d.data, offset, h), 10))
+	case decode.Uint:
+		d.w.WriteString(strconv.FormatUint(decode.ReadUint(d.data, offset, h), 10))
+	case decode.Float:
+		if exactNaN(h, payload) {
+			d.w.WriteString("NaN(")
+			writeHex(d.w, payload)
+			d.w.WriteByte(')')
+		} else {
+			d.w.WriteString(formatFloat(decode.ReadFloat(d.data, offset, h), floatBitSize(h)))
+		}
+	case decode.String:
+		d.w.WriteString(strconv.Quote(string(payload)))
+	case decode.Binary:
+		writeHex(d.w, payload)
+	}
+}
+
+// timestamp decodes the payload of the external object with header h,
+// if it's a valid timestamp.
+func timestamp(h decode.Header, payload []byte) (time.Time, bool) {
+	if h.ExtType != encode.Timestamp {
+		return time.Time{}, false
+	}
+	t, err := decode.ReadTimestamp(payload)
+	return t, err == nil
+}
+
+// rfc3339Timestamp decodes the payload of the external object with header h, if it's
+// a timestamp that RFC 3339 can represent and that is encoded back to the same payload.
+func rfc3339Timestamp(h decode.Header, payload []byte) (time.Time, bool) {
+	t, ok := timestamp(h, payload)
+	if !ok || t.Year() < 0 || t.Year() > 9999 {
+		return time.Time{}, false
+	}
+	return t, bytes.Equal(encode.NewTimestamp(t).Data, payload)
+}
+
+// writeHex writes b as h'...'.
+func writeHex(w *bufio.Writer, b []byte) {
+	w.WriteString("h'")
+	hex.NewEncoder(w).Write(b)
+	w.WriteByte('\'')
+}
+
+// floatBitSize returns the size in bits of the float object with header h.
+func floatBitSize(h decode.Header) int {
+	if h.Code == types.Float32 {
+		return 32
+	}
+	return 64
+}
+
+// exactNaN reports whether the float payload is a NaN with other bits than the one
+// that ParseDiag encodes for NaN, so that its payload must be written.
+func exactNaN(h decode.Header, payload []byte) bool {
+	if h.Code == types.Float32 {
+		bits := binary.BigEndian.Uint32(payload)
+		return bits != math.Float32bits(float32(math.NaN())) && math.IsNaN(float64(math.Float32frombits(bits)))
+	}
+	bits := binary.BigEndian.Uint64(payload)
+	return bits != math.Float64bits(math.NaN()) && math.IsNaN(math.Float64frombits(bits))
+}
+
+// formatFloat returns the shortest representation of f that has a decimal point or an exponent,
+// so that it can't be confused with an integer.
+func formatFloat(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// canonical reports whether the object with header h that starts at data[offset] uses
+// the smallest format for its value, so that ParseDiag encodes its diagnostic notation
+// without suffix back to the same bytes. Non-negative integers are compared with the uint formats.
+func canonical(data []byte, offset int, h decode.Header) bool {
+	switch h.Kind {
+	case decode.Uint:
+		return AppendUint(nil, decode.ReadUint(data, offset, h))[0] == h.Code
+	case decode.Int:
+		if i := decode.ReadInt(data, offset, h); i < 0 {
+			return AppendInt(nil, i)[0] == h.Code
+		}
+		return false
+	case decode.Float:
+		if exactNaN(h, data[offset+h.Size:offset+h.Size+h.Payload()]) {
+			// The width is given by the payload
+			return true
+		}
+		// ParseDiag must choose the same format from the written value
+		f, _ := parseFloat(formatFloat(decode.ReadFloat(data, offset, h), floatBitSize(h)), 64)
+		return AppendFloat(nil, f)[0] == h.Code
+	case decode.String:
+		return h.Size == lengthFieldSize(h.Length, types.Max5Bit)+1
+	case decode.Binary:
+		return h.Size == lengthFieldSize(h.Length, -1)+1
+	case decode.Ext:
+		switch h.Length {
+		case 1, 2, 4, 8, 16:
+			return h.Size == 2
+		}
+		return h.Size == lengthFieldSize(h.Length, -1)+2
+	case decode.Array, decode.Map:
+		// Arrays and maps have the same header sizes
+		header, err := types.ArrayHeader(h.Length)
+		return err == nil && len(header) == h.Size
+	default:
+		return true
+	}
+}
+
+// lengthFieldSize returns the size of the smallest length field for length.
+// Lengths up to fixed fit in the first byte.
+func lengthFieldSize(length int, fixed int) int {
+	switch {
+	case length <= fixed:
+		return 0
+	case length <= math.MaxUint8:
+		return 1
+	case length <= math.MaxUint16:
+		return 2
+	default:
+		return 4
+	}
+}
+
+// formatSuffix returns the diagnostic notation suffix of the format identified by code.
+func formatSuffix(code byte) string {
+	switch code {
+	case types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		return "_u" + strconv.Itoa(8<<(code-types.Uint8))
+	case types.Int8, types.Int16, types.Int32, types.Int64:
+		return "_i" + strconv.Itoa(8<<(code-types.Int8))
+	case types.Float32:
+		return "f32"
+	case types.Float64:
+		return "f64"
+	case types.Str8, types.Bin8, types.Ext8:
+		return "_8"
+	case types.Str16, types.Bin16, types.Ext16, types.Array16, types.Map16:
+		return "_16"
+	case types.Str32, types.Bin32, types.Ext32, types.Array32, types.Map32:
+		return "_32"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,90 @@
+package sbor
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// dumpTestData is {"a": [1, -8, 3.5, h'ccff', ext(16, h'74657374'), ts, nil], 2_u16: "x"_8},
+// with ts equal to 2022-03-06T15:20:00Z.
+var dumpTestData = []byte{
+	0x82, 0xA1, 0x61, 0x97, 0x01, 0xF8, 0xCA, 0x40, 0x60, 0x00, 0x00, 0xC4, 0x02, 0xCC, 0xFF,
+	0xD6, 0x10, 0x74, 0x65, 0x73, 0x74, 0xD6, 0xFF, 0x62, 0x24, 0xD1, 0x20, 0xC0,
+	0xCD, 0x00, 0x02, 0xD9, 0x01, 0x78,
+}
+
+func TestDump(t *testing.T) {
+	expected := `0000  fixmap len=2
+0001    fixstr len=1 "a"
+0003      fixarray len=7
+0004        positive fixint 1
+0005        negative fixint -8
+0006        float32 3.5
+000b        bin8 len=2 h'ccff'
+000f        fixext4 len=4 type=16 h'74657374'
+0015        fixext4 len=4 type=-1 2022-03-06T15:20:00Z
+001b        nil
+001c    uint16 2
+001f      str8 len=1 "x"
+`
+
+	var b bytes.Buffer
+	if err := Dump(&b, dumpTestData, DumpOptions{}); err != nil {
+		t.Fatalf("Dump Error: %v", err)
+	}
+	if b.String() != expected {
+		t.Errorf("Invalid result. Function returned\n%s\nExpected\n%s", b.String(), expected)
+	}
+}
+
+func TestDump_Compact(t *testing.T) {
+	data := []struct {
+		input    []byte
+		expected string
+		name     string
+	}{
+		{input: dumpTestData, expected: `{"a": [1, -8, 3.5, h'ccff', ext(16, h'74657374'), ts("2022-03-06T15:20:00Z"), nil], 2_u16: "x"_8}`, name: "Map"},
+		{input: []byte{0xD0, 0x05}, expected: "5_i8", name: "Positive int8"},
+		{input: []byte{0xCB, 0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, expected: "1.5f64", name: "Float64"},
+		{input: []byte{0xCA, 0x3F, 0x80, 0x00, 0x00}, expected: "1.0", name: "Integral float"},
+		{input: []byte{0xCB, 0x3F, 0xB9, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A}, expected: "0.1", name: "Float64 not float32"},
+		{input: []byte{0xCA, 0x3D, 0xCC, 0xCC, 0xCD}, expected: "0.1f32", name: "Float32 not exact"},
+		{input: []byte{0xDC, 0x00, 0x01, 0xC3}, expected: "[true]_16", name: "Array16"},
+		{input: []byte{0xC7, 0x00, 0x05}, expected: "ext(5, h'')", name: "Empty ext"},
+		{input: []byte{0xC7, 0x04, 0x05, 0x01, 0x02, 0x03, 0x04}, expected: "ext(5, h'01020304')_8", name: "Ext8"},
+		{input: []byte{0xA2, 0xC3, 0x28}, expected: `"\xc3("`, name: "Invalid UTF-8"},
+		{input: []byte{0xC7, 0x0C, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x62, 0x24, 0xD1, 0x20}, expected: "ext(-1, h'00000000000000006224d120')", name: "Timestamp 96"},
+		{input: []byte{0xC7, 0x0C, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x3A, 0xFF, 0xF4, 0x41, 0x80}, expected: "ext(-1, h'000000000000003afff44180')", name: "Timestamp year 10000"},
+		{input: []byte{0xCA, 0x7F, 0xE3, 0x17, 0x02}, expected: "NaN(h'7fe31702')", name: "NaN payload"},
+		{input: []byte{0xCB, 0x7F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, expected: "NaN", name: "NaN"},
+		{input: []byte{0xC0, 0x01}, expected: "nil\n1", name: "Stream"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := Dump(&b, test.input, DumpOptions{Compact: true}); err != nil {
+				t.Fatalf("Dump Error: %v", err)
+			}
+			if result := b.String(); result != test.expected+"\n" {
+				t.Errorf("Invalid result. Function returned %s. Expected %s.", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestDump_Error(t *testing.T) {
+	var b bytes.Buffer
+	err := Dump(&b, []byte{0x92, 0x01, 0xA3, 0x66}, DumpOptions{Indent: "\t"})
+
+	var syntax SyntaxError
+	if !errors.As(err, &syntax) || syntax.Offset != 2 {
+		t.Errorf("Invalid error. Function returned %v. Expected offset %d.", err, 2)
+	}
+
+	expected := "0000  fixarray len=2\n0001  \tpositive fixint 1\n"
+	if b.String() != expected {
+		t.Errorf("Invalid partial result. Function returned %q. Expected %q.", b.String(), expected)
+	}
+}
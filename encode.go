@@ -17,9 +17,10 @@ import (
 //
 // Nil values encode as MessagePack nil (for example an empty pointer).
 //
-// Integer values encode as MessagePack int.
+// Integer values encode as MessagePack int, using the smallest format that contains the value.
 //
-// Floating point values encode as MessagePack float.
+// Floating point values encode as MessagePack float, using a float32 when it represents the
+// value exactly. An Encoder can use fixed formats for the numbers, see Encoder.SetFloatPolicy.
 //
 // String values encode as MessagePack string.
 //
@@ -34,10 +35,31 @@ import (
 // The encoding of each struct field can be customized by the format string stored under the "sbor"
 // key in the struct field's tag. The format string gives the name of the field, possibly followed by
 // a comma-separated list of options. The name may be empty in order to specify options without overriding
-// the default field name.
+// the default field name. An Encoder can read the tags under other keys too, like "json",
+// see Encoder.SetTagName.
 //
 // The "omitempty" option specifies that the field should be omitted from the encoding if the field has an
 // empty value, defined as false, 0, a nil pointer, a nil interface value, and any empty array, slice, map, or string.
+// A struct is never empty, and an array is empty only if its length is zero: use "omitzero" to omit them
+// when all their elements are zero.
+//
+// The "omitzero" option specifies that the field should be omitted from the encoding if the field has a zero
+// value. If the type of the field has an "IsZero() bool" method, like time.Time, its result decides whether the
+// value is zero, else the value is zero if it's the zero value of its type, so an empty but non-nil slice or map
+// is still encoded. A nil pointer or interface value is always zero.
+//
+// The "string" option specifies that a boolean, integer or floating point field, or a pointer to one of them,
+// is encoded as a MessagePack string with its decimal form, like "true" or "18446744073709551615", instead of
+// a boolean or a number. It's useful for the consumers that can't represent 64-bit integers exactly, like
+// JavaScript, and it's ignored for the fields of other types.
+//
+// The "float=shortest", "float=preserve", "float=64", "int=shortest", "int=preserve" and "signed" options
+// choose the format of the numbers in the field value, overriding the policies of the Encoder.
+// See Encoder.SetFloatPolicy, Encoder.SetIntPolicy and Encoder.SetPreserveSign.
+//
+// The "time=ext", "time=unix", "time=unixmilli", "time=unixnano", "time=unixfloat", "time=rfc3339" and
+// "time=zoned" options choose the format of the time.Time values in the field, overriding the time policy of the
+// Encoder. See Encoder.SetTimePolicy.
 //
 // The "structarray" option specifies that the current struct must be encoded as an array instead of a map,
 // so all the keys will be discarded.
@@ -59,6 +81,12 @@ import (
 //   // Note the leading comma.
 //   Field int `sbor:",omitempty"`
 //
+//   // Field appears in MessagePack as the string "42" for the value 42.
+//   Field int64 `sbor:",string"`
+//
+//   // Field is skipped if Field.IsZero() returns true.
+//   Field time.Time `sbor:",omitzero"`
+//
 //   // Field appears in MessagePack as key "Field" (the default).
 //   // Whole struct is now an array.
 //   Field int `sbor:",structarray"`
@@ -100,7 +128,9 @@ import (
 //
 // Map values encode as MessagePack maps. The map's key type can be any type
 // supported by MessagePack (int, float, string, map, array, ...).
-// Keys of any type are used directly.
+// Keys of any type are used directly. The entries of the maps whose keys are arrays,
+// structs or pointers are always sorted like in canonical mode, see Encoder.SetCanonical,
+// so that their encoding doesn't depend on the iteration order of the Go map.
 //
 // Pointer values encode as the value pointed to.
 // A nil pointer encodes as the nil MessagePack value.
@@ -116,7 +146,10 @@ import (
 // to avoid an infinite loop.
 //
 func Marshal(v interface{}) ([]byte, error) {
-	state := encode.NewEncoderState()
+	return marshal(encode.NewEncoderState(), v)
+}
+
+func marshal(state *encode.EncoderState, v interface{}) ([]byte, error) {
 	result := state.TypeWrapper(reflect.ValueOf(v))
 	bufferResult := bytes.NewBuffer(make([]byte, 0, result.Len()))
 	_, err := result.WriteTo(bufferResult)
@@ -0,0 +1,32 @@
+package sbor
+
+import "github.com/ErikPelli/sbor/internal/utils"
+
+// SyntaxError describes invalid MessagePack data, with the offset of the
+// offending byte: the first byte of an invalid or truncated object, or the
+// first invalid byte of a string.
+type SyntaxError = utils.SyntaxError
+
+// NotFoundError is returned when a key or an index doesn't exist in
+// a MessagePack message.
+type NotFoundError = utils.NotFoundError
+
+// DiagSyntaxError describes an invalid diagnostic notation text, with the
+// offset of the offending character.
+type DiagSyntaxError = utils.DiagSyntaxError
+
+// UnmarshalTypeError describes a MessagePack value that can't be stored in
+// a Go value of a specific type, with the offset of the object.
+type UnmarshalTypeError = utils.UnmarshalTypeError
+
+// DuplicatedKeyError describes a key that appears more than once in a map
+// or in a struct, where it isn't allowed.
+type DuplicatedKeyError = utils.DuplicatedKeyError
+
+// UnknownFieldError describes a map key that doesn't match any field of a Go struct,
+// when the Decoder disallows the unknown fields, with the offset of the key.
+type UnknownFieldError = utils.UnknownFieldError
+
+// MissingFieldError describes a field of a Go struct with the "required" option
+// that is absent from a MessagePack map or array, with the offset of the map or array.
+type MissingFieldError = utils.MissingFieldError
@@ -0,0 +1,68 @@
+package ext
+
+import (
+	"encoding/binary"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"math/big"
+)
+
+func encodeBigInt(i interface{}) ([]byte, error) {
+	n := i.(*big.Int)
+	sign := byte(0)
+	if n.Sign() < 0 {
+		sign = 1
+	}
+	return append([]byte{sign}, n.Bytes()...), nil
+}
+
+func decodeBigInt(data []byte, v interface{}) error {
+	if len(data) == 0 || data[0] > 1 {
+		return utils.InvalidTypeError{Type: "big integer with invalid sign"}
+	}
+	n := v.(*big.Int).SetBytes(data[1:])
+	if data[0] == 1 {
+		n.Neg(n)
+	}
+	return nil
+}
+
+// maxBigFloatPrec is the maximum precision in bits of a big.Float payload. The decoding
+// computes the value with the precision of the payload, so it can't be trusted up to big.MaxPrec.
+const maxBigFloatPrec = 1 << 16
+
+func encodeBigFloat(i interface{}) ([]byte, error) {
+	f := i.(*big.Float)
+	if f.Prec() > maxBigFloatPrec {
+		return nil, utils.InvalidTypeError{Type: "big float with precision over 65536 bits"}
+	}
+	result := make([]byte, 4, 16)
+	binary.BigEndian.PutUint32(result, uint32(f.Prec()))
+	return append(result, f.Text('g', -1)...), nil
+}
+
+func decodeBigFloat(data []byte, v interface{}) error {
+	if len(data) < 5 {
+		return utils.InvalidTypeError{Type: "big float with invalid length"}
+	}
+	prec := binary.BigEndian.Uint32(data)
+	if prec > maxBigFloatPrec {
+		return utils.InvalidTypeError{Type: "big float with invalid precision"}
+	}
+
+	f := v.(*big.Float).SetPrec(uint(prec))
+	if _, _, err := f.Parse(string(data[4:]), 10); err != nil {
+		return utils.InvalidTypeError{Type: "big float with invalid value (" + err.Error() + ")"}
+	}
+	return nil
+}
+
+func encodeBigRat(i interface{}) ([]byte, error) {
+	return []byte(i.(*big.Rat).String()), nil
+}
+
+func decodeBigRat(data []byte, v interface{}) error {
+	if _, ok := v.(*big.Rat).SetString(string(data)); !ok {
+		return utils.InvalidTypeError{Type: "big rational with invalid value"}
+	}
+	return nil
+}
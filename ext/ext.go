@@ -0,0 +1,94 @@
+// Package ext contains the external types for common types of the Go standard library,
+// to register in an Encoder, a Decoder or a Config with their Use method:
+//
+//   e := sbor.NewEncoder(w)
+//   if err := e.Use(ext.StdPack); err != nil {
+//       ...
+//   }
+//
+// StdPack uses the codes of DefaultNumbering, and Pack builds the same types with
+// other codes, to avoid a conflict with the external types of an application.
+// Both the sides of a communication must use the same codes.
+//
+// The payloads are big-endian and they don't depend on Go, so other languages can read them:
+//
+//   complex64       real and imaginary parts, as float32
+//   complex128      real and imaginary parts, as float64
+//   time.Duration   nanoseconds, as int64
+//   *big.Int        sign byte (0 or 1 for negative numbers), then the absolute value
+//   *big.Float      precision in bits as uint32, at most 65536, then the decimal value as text
+//   *big.Rat        fraction as text, like "-3/4"
+//   netip.Addr      4 or 16 bytes of the address, then the IPv6 zone if any
+//   netip.Prefix    the address as netip.Addr, then the prefix length as a byte
+//   net.HardwareAddr  bytes of the address
+//   time.Time       seconds since the Unix epoch as int64, nanoseconds as uint32,
+//                   offset of the zone from UTC in seconds as int32, then the IANA
+//                   name of the location, empty for time.Local
+//
+// The time.Time type replaces the timestamp external type when encoding, to preserve the
+// time zone, and when decoding time.Time accepts both. Its payload is the same written by
+// the sbor.TimeZoned time policy.
+package ext
+
+import (
+	"github.com/ErikPelli/sbor"
+	"math/big"
+	"net"
+	"net/netip"
+	"time"
+)
+
+// Numbering contains the external type codes used by Pack, between 0 and 127.
+type Numbering struct {
+	Complex64    int8
+	Complex128   int8
+	Duration     int8
+	BigInt       int8
+	BigFloat     int8
+	BigRat       int8
+	Addr         int8
+	Prefix       int8
+	HardwareAddr int8
+	Time         int8
+}
+
+// DefaultNumbering contains the external type codes used by StdPack.
+var DefaultNumbering = Numbering{
+	Complex64:    80,
+	Complex128:   81,
+	Duration:     82,
+	BigInt:       83,
+	BigFloat:     84,
+	BigRat:       85,
+	Addr:         86,
+	Prefix:       87,
+	HardwareAddr: 88,
+	Time:         sbor.DefaultZonedTimeType,
+}
+
+// StdPack contains all the external types of this package, with the codes of DefaultNumbering.
+var StdPack = Pack(DefaultNumbering)
+
+// Pack returns all the external types of this package, with the codes of n.
+func Pack(n Numbering) sbor.ExtPack {
+	return sbor.ExtPack{
+		{ID: n.Complex64, Value: complex64(0), Encoder: encoder(encodeComplex64), Decoder: decoder(decodeComplex64)},
+		{ID: n.Complex128, Value: complex128(0), Encoder: encoder(encodeComplex128), Decoder: decoder(decodeComplex128)},
+		{ID: n.Duration, Value: time.Duration(0), Encoder: encoder(encodeDuration), Decoder: decoder(decodeDuration)},
+		{ID: n.BigInt, Value: (*big.Int)(nil), Encoder: encoder(encodeBigInt), Decoder: decoder(decodeBigInt)},
+		{ID: n.BigFloat, Value: (*big.Float)(nil), Encoder: encoder(encodeBigFloat), Decoder: decoder(decodeBigFloat)},
+		{ID: n.BigRat, Value: (*big.Rat)(nil), Encoder: encoder(encodeBigRat), Decoder: decoder(decodeBigRat)},
+		{ID: n.Addr, Value: netip.Addr{}, Encoder: encoder(encodeAddr), Decoder: decoder(decodeAddr)},
+		{ID: n.Prefix, Value: netip.Prefix{}, Encoder: encoder(encodePrefix), Decoder: decoder(decodePrefix)},
+		{ID: n.HardwareAddr, Value: net.HardwareAddr(nil), Encoder: encoder(encodeHardwareAddr), Decoder: decoder(decodeHardwareAddr)},
+		{ID: n.Time, Value: time.Time{}, Encoder: encoder(encodeTime), Decoder: decoder(decodeTime)},
+	}
+}
+
+func encoder(f func(i interface{}) ([]byte, error)) sbor.CustomEncoder {
+	return sbor.CustomEncoder{Encoder: f}
+}
+
+func decoder(f func(data []byte, v interface{}) error) sbor.CustomDecoder {
+	return sbor.CustomDecoder{Decoder: f}
+}
@@ -0,0 +1,189 @@
+package ext
+
+import (
+	"bytes"
+	"errors"
+	"github.com/ErikPelli/sbor"
+	"math"
+	"math/big"
+	"net"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// mustParseDiag returns the MessagePack encoding of the diagnostic notation s.
+func mustParseDiag(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := sbor.ParseDiag(s)
+	if err != nil {
+		t.Fatalf("ParseDiag Error: %v", err)
+	}
+	return data
+}
+
+func TestStdPack(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	bigInt, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	bigFloat, _, _ := big.ParseFloat("1.5e100", 10, 100, big.ToNearestEven)
+
+	data := []struct {
+		value  interface{}
+		vector string
+		name   string
+	}{
+		{value: complex64(complex(9.5, -1)), vector: "ext(80, h'41180000bf800000')", name: "complex64"},
+		{value: complex(math.Inf(1), 0.5), vector: "ext(81, h'7ff00000000000003fe0000000000000')", name: "complex128"},
+		{value: -90 * time.Second, vector: "ext(82, h'ffffffeb0b94fc00')", name: "Duration"},
+		{value: bigInt, vector: "ext(83, h'01018ee90ff6c373e0ee4e3f0ad2')", name: "big.Int"},
+		{value: big.NewInt(0), vector: "ext(83, h'00')", name: "big.Int zero"},
+		{value: bigFloat, vector: `ext(84, h'00000064312e35652b313030')`, name: "big.Float"},
+		{value: big.NewRat(-3, 4), vector: `ext(85, h'2d332f34')`, name: "big.Rat"},
+		{value: netip.MustParseAddr("192.168.1.2"), vector: "ext(86, h'c0a80102')", name: "IPv4"},
+		{value: netip.MustParseAddr("fe80::1%eth0"), vector: "ext(86, h'fe80000000000000000000000000000165746830')", name: "IPv6 with zone"},
+		{value: netip.MustParsePrefix("10.0.0.0/8"), vector: "ext(87, h'0a00000008')", name: "Prefix"},
+		{value: net.HardwareAddr{0x00, 0x1A, 0x2B, 0x3C, 0x4D, 0x5E}, vector: "ext(88, h'001a2b3c4d5e')", name: "HardwareAddr"},
+		{value: time.Date(2022, 7, 1, 12, 30, 0, 5, rome), vector: "ext(89, h'0000000062becca80000000500001c204575726f70652f526f6d65')", name: "Time"},
+		{value: time.Date(2022, 7, 1, 12, 30, 0, 0, time.UTC), vector: "ext(89, h'0000000062bee8c80000000000000000555443')", name: "Time UTC"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			vector := mustParseDiag(t, test.vector)
+
+			var b bytes.Buffer
+			e := sbor.NewEncoder(&b)
+			if err := e.Use(StdPack); err != nil {
+				t.Fatalf("Use Error: %v", err)
+			}
+			if err := e.Encode(test.value); err != nil {
+				t.Fatalf("Encoder Error: %v", err)
+			}
+			if !bytes.Equal(b.Bytes(), vector) {
+				t.Errorf("Invalid result. Function returned %x. Expected %x.", b.Bytes(), vector)
+			}
+
+			d := sbor.NewDecoder(bytes.NewReader(vector))
+			if err := d.Use(StdPack); err != nil {
+				t.Fatalf("Use Error: %v", err)
+			}
+			result := reflect.New(reflect.TypeOf(test.value))
+			if err := d.Decode(result.Interface()); err != nil {
+				t.Fatalf("Decoder Error: %v", err)
+			}
+			if !equal(result.Elem().Interface(), test.value) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result.Elem().Interface(), test.value)
+			}
+		})
+	}
+}
+
+// equal reports whether the values a and b are equal, comparing the numbers and the times by value.
+func equal(a interface{}, b interface{}) bool {
+	switch b := b.(type) {
+	case *big.Int:
+		return a.(*big.Int).Cmp(b) == 0
+	case *big.Float:
+		return a.(*big.Float).Cmp(b) == 0 && a.(*big.Float).Prec() == b.Prec()
+	case *big.Rat:
+		return a.(*big.Rat).Cmp(b) == 0
+	case time.Time:
+		return a.(time.Time).Equal(b) && a.(time.Time).Location().String() == b.Location().String()
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func TestPack(t *testing.T) {
+	type Test struct {
+		Timeout time.Duration
+		Created time.Time
+		Values  []complex64
+	}
+
+	numbering := DefaultNumbering
+	numbering.Duration = 1
+	config, err := sbor.NewConfig().Use(Pack(numbering))
+	if err != nil {
+		t.Fatalf("Use Error: %v", err)
+	}
+
+	input := Test{Timeout: time.Millisecond, Created: time.Date(2000, 1, 1, 0, 0, 0, 0, time.FixedZone("X", 3600)), Values: []complex64{1i}}
+	encoded, err := config.Marshal(input)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+
+	expected := mustParseDiag(t, `{"Timeout": ext(1, h'00000000000f4240'), "Created": ext(89, h'00000000386d35700000000000000e1058'), "Values": [ext(80, h'000000003f800000')]}`)
+	if !bytes.Equal(encoded, expected) {
+		t.Errorf("Invalid result. Function returned %x. Expected %x.", encoded, expected)
+	}
+
+	var result Test
+	if err = config.Unmarshal(encoded, &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+	if result.Timeout != input.Timeout || !equal(result.Created, input.Created) || !reflect.DeepEqual(result.Values, input.Values) {
+		t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, input)
+	}
+
+	// The standard timestamp and the integers are still accepted
+	if err = config.Unmarshal(mustParseDiag(t, `{"Timeout": 5, "Created": ts("2000-01-01T00:00:00Z")}`), &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+	if result.Timeout != 5 || !result.Created.Equal(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Invalid result. Function returned %+v.", result)
+	}
+}
+
+func TestStdPack_Error(t *testing.T) {
+	data := []struct {
+		vector string
+		target interface{}
+	}{
+		{vector: "ext(80, h'01')", target: new(complex64)},
+		{vector: "ext(81, h'01')", target: new(complex128)},
+		{vector: "ext(82, h'01')", target: new(time.Duration)},
+		{vector: "ext(83, h'02')", target: new(big.Int)},
+		{vector: "ext(84, h'00')", target: new(big.Float)},
+		{vector: "ext(84, h'0000004078')", target: new(big.Float)},
+		{vector: "ext(84, h'00010001302e31')", target: new(big.Float)},
+		{vector: "ext(84, h'ffffffff302e31')", target: new(big.Float)},
+		{vector: "ext(85, h'78')", target: new(big.Rat)},
+		{vector: "ext(86, h'010203')", target: new(netip.Addr)},
+		{vector: "ext(87, h'01020308')", target: new(netip.Prefix)},
+		{vector: "ext(89, h'01')", target: new(time.Time)},
+	}
+
+	config, err := sbor.NewConfig().Use(StdPack)
+	if err != nil {
+		t.Fatalf("Use Error: %v", err)
+	}
+
+	for _, test := range data {
+		err := config.Unmarshal(mustParseDiag(t, test.vector), test.target)
+		var typeError sbor.UnmarshalTypeError
+		if err == nil || errors.As(err, &typeError) {
+			t.Errorf("Payload error was expected for %s. Function returned %v.", test.vector, err)
+		}
+	}
+
+	if _, err = config.Marshal(new(big.Float).SetPrec(1<<16 + 1)); err == nil {
+		t.Error("Error was expected with a precision over the limit.")
+	}
+	var f big.Float
+	if err = config.Unmarshal(mustParseDiag(t, "ext(84, h'00010000302e31')"), &f); err != nil || f.Prec() != 1<<16 {
+		t.Errorf("Invalid result with the maximum precision. Function returned %v, %v.", f.Prec(), err)
+	}
+
+	numbering := DefaultNumbering
+	numbering.Addr = -5
+	if _, err = sbor.NewConfig().Use(Pack(numbering)); err == nil {
+		t.Error("Error was expected with an invalid code.")
+	}
+}
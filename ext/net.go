@@ -0,0 +1,38 @@
+package ext
+
+import (
+	"github.com/ErikPelli/sbor/internal/utils"
+	"net"
+	"net/netip"
+)
+
+func encodeAddr(i interface{}) ([]byte, error) {
+	return i.(netip.Addr).MarshalBinary()
+}
+
+func decodeAddr(data []byte, v interface{}) error {
+	if err := v.(*netip.Addr).UnmarshalBinary(data); err != nil {
+		return utils.InvalidTypeError{Type: "IP address with invalid value (" + err.Error() + ")"}
+	}
+	return nil
+}
+
+func encodePrefix(i interface{}) ([]byte, error) {
+	return i.(netip.Prefix).MarshalBinary()
+}
+
+func decodePrefix(data []byte, v interface{}) error {
+	if err := v.(*netip.Prefix).UnmarshalBinary(data); err != nil {
+		return utils.InvalidTypeError{Type: "IP prefix with invalid value (" + err.Error() + ")"}
+	}
+	return nil
+}
+
+func encodeHardwareAddr(i interface{}) ([]byte, error) {
+	return i.(net.HardwareAddr), nil
+}
+
+func decodeHardwareAddr(data []byte, v interface{}) error {
+	*v.(*net.HardwareAddr) = data
+	return nil
+}
@@ -0,0 +1,54 @@
+package ext
+
+import (
+	"encoding/binary"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"math"
+	"time"
+)
+
+func encodeComplex64(i interface{}) ([]byte, error) {
+	c := i.(complex64)
+	result := make([]byte, 8)
+	binary.BigEndian.PutUint32(result, math.Float32bits(real(c)))
+	binary.BigEndian.PutUint32(result[4:], math.Float32bits(imag(c)))
+	return result, nil
+}
+
+func decodeComplex64(data []byte, v interface{}) error {
+	if len(data) != 8 {
+		return utils.InvalidTypeError{Type: "complex64 with invalid length"}
+	}
+	*v.(*complex64) = complex(math.Float32frombits(binary.BigEndian.Uint32(data)), math.Float32frombits(binary.BigEndian.Uint32(data[4:])))
+	return nil
+}
+
+func encodeComplex128(i interface{}) ([]byte, error) {
+	c := i.(complex128)
+	result := make([]byte, 16)
+	binary.BigEndian.PutUint64(result, math.Float64bits(real(c)))
+	binary.BigEndian.PutUint64(result[8:], math.Float64bits(imag(c)))
+	return result, nil
+}
+
+func decodeComplex128(data []byte, v interface{}) error {
+	if len(data) != 16 {
+		return utils.InvalidTypeError{Type: "complex128 with invalid length"}
+	}
+	*v.(*complex128) = complex(math.Float64frombits(binary.BigEndian.Uint64(data)), math.Float64frombits(binary.BigEndian.Uint64(data[8:])))
+	return nil
+}
+
+func encodeDuration(i interface{}) ([]byte, error) {
+	result := make([]byte, 8)
+	binary.BigEndian.PutUint64(result, uint64(i.(time.Duration)))
+	return result, nil
+}
+
+func decodeDuration(data []byte, v interface{}) error {
+	if len(data) != 8 {
+		return utils.InvalidTypeError{Type: "duration with invalid length"}
+	}
+	*v.(*time.Duration) = time.Duration(binary.BigEndian.Uint64(data))
+	return nil
+}
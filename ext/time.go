@@ -0,0 +1,19 @@
+package ext
+
+import (
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"time"
+)
+
+func encodeTime(i interface{}) ([]byte, error) {
+	return encode.ZonedTimeBytes(i.(time.Time)), nil
+}
+
+func decodeTime(data []byte, v interface{}) error {
+	t, err := decode.ReadZonedTime(data)
+	if err == nil {
+		*v.(*time.Time) = t
+	}
+	return err
+}
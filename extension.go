@@ -0,0 +1,77 @@
+package sbor
+
+// ExtType describes how a Go type is encoded and decoded as an external type,
+// so that it can be registered with the Use methods.
+//
+// Value is an instance of the type, or a pointer to it, like in SetExternalType.
+// Encoder and Decoder can be left empty if a pointer to the type implements
+// MessagePackCustom interface.
+type ExtType struct {
+	ID      int8
+	Value   interface{}
+	Encoder CustomEncoder
+	Decoder CustomDecoder
+}
+
+// An ExtPack is a set of external types, registered at once with the Use methods,
+// like the standard library types of the ext package.
+type ExtPack []ExtType
+
+// customEncoders returns the CustomEncoder of t, if it has one.
+func (t ExtType) customEncoders() []CustomEncoder {
+	if t.Encoder.Encoder == nil && t.Encoder.Write == nil {
+		return nil
+	}
+	return []CustomEncoder{t.Encoder}
+}
+
+// customDecoders returns the CustomDecoder of t, if it has one.
+func (t ExtType) customDecoders() []CustomDecoder {
+	if t.Decoder.Decoder == nil && t.Decoder.Reader == nil {
+		return nil
+	}
+	return []CustomDecoder{t.Decoder}
+}
+
+// Use registers the external types of the packs in this Encoder, in order,
+// as SetExternalType does. It stops at the first invalid type.
+func (e *Encoder) Use(packs ...ExtPack) error {
+	for _, pack := range packs {
+		for _, t := range pack {
+			if err := setExternalType(e.state, t.ID, t.Value, t.customEncoders()...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Use registers the external types of the packs in this Decoder, in order,
+// as SetExternalType does. It stops at the first invalid type.
+func (d *Decoder) Use(packs ...ExtPack) error {
+	for _, pack := range packs {
+		for _, t := range pack {
+			if err := setExternalDecoder(d.state, t.ID, t.Value, t.customDecoders()...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Use returns a copy of c with the external types of the packs registered,
+// for both encoding and decoding.
+func (c *Config) Use(packs ...ExtPack) (*Config, error) {
+	result := c.copy()
+	for _, pack := range packs {
+		for _, t := range pack {
+			if err := setExternalType(result.encoder, t.ID, t.Value, t.customEncoders()...); err != nil {
+				return nil, err
+			}
+			if err := setExternalDecoder(result.decoder, t.ID, t.Value, t.customDecoders()...); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
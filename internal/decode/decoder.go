@@ -0,0 +1,971 @@
+package decode
+
+import (
+	"bytes"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// DecoderState contains the options used to decode MessagePack data into Go values.
+type DecoderState struct {
+	TagNames              []string          // Keys of the struct field's tag, in order of precedence
+	CompatMode            bool              // Old specification: strings contain raw bytes
+	NoCopy                bool              // Byte slices reference the input data instead of a copy
+	InternKeys            bool              // Equal string map keys share the same string
+	MaxDepth              int               // Maximum nesting depth of arrays and maps, if lower than the package limit
+	MaxLength             int               // Maximum number of elements or bytes of an object, if greater than zero
+	Time                  encode.TimePolicy // Unit of the integer times: milliseconds, nanoseconds or else seconds
+	ZonedTimeType         int8              // External type code of the times with their zone
+	DuplicateKeys         DuplicatePolicy   // Handling of the keys repeated in a map, for Go maps, structs and pairs
+	DisallowUnknownFields bool              // Error for the map keys without a struct field and the arrays of another length
+
+	keys            map[string]string // Interned map keys
+	extUserHandlers map[reflect.Type]ExtUserHandler
+}
+
+// ExtUserHandler is a function that handle a custom decode defined by the user,
+// from a MessagePack External. Decoder receives the payload, or if it's nil Reader
+// receives a reader of the payload and its size, along with a pointer to the value
+// to fill. The part of the payload not read by Reader is skipped.
+type ExtUserHandler struct {
+	Type    int8
+	Decoder func(data []byte, v interface{}) error
+	Reader  func(r io.Reader, size int, v interface{}) error
+}
+
+// maxInternedKeys is the maximum number of map keys interned by a DecoderState.
+const maxInternedKeys = 4096
+
+// builtinDecoders contains the decoders of the public types that
+// wrap a MessagePack type, registered by the sbor package.
+var builtinDecoders = make(map[reflect.Type]func(d *DecoderState, data []byte, offset int, v reflect.Value, depth int) (int, error))
+
+// RegisterBuiltin associate a type with the function that decodes the object that starts at
+// data[offset], at the given nesting depth, into a value of that type and returns the offset
+// of the first byte after it, for every DecoderState. It must be called only during the
+// package initialization.
+func RegisterBuiltin(typeInvolved interface{}, decoder func(d *DecoderState, data []byte, offset int, v reflect.Value, depth int) (int, error)) {
+	builtinDecoders[reflect.TypeOf(typeInvolved)] = decoder
+}
+
+func NewDecoderState() *DecoderState {
+	return &DecoderState{
+		TagNames:      encode.DefaultTagNames,
+		ZonedTimeType: encode.DefaultZonedTimeType,
+	}
+}
+
+// Decode decodes the object that starts at data[offset] into v, that must be settable.
+// It returns the offset of the first byte after the object.
+// Strings are always copied, and the byte slices too unless NoCopy is set.
+func (d *DecoderState) Decode(data []byte, offset int, v reflect.Value) (int, error) {
+	return d.decode(data, offset, v, 0)
+}
+
+func (d *DecoderState) decode(data []byte, offset int, v reflect.Value, depth int) (int, error) {
+	// Public types with a MessagePack representation
+	if decoder, ok := builtinDecoders[v.Type()]; ok {
+		return decoder(d, data, offset, v, depth)
+	}
+
+	h, err := ReadHeader(data, offset)
+	if err != nil {
+		return offset, err
+	}
+	if err = d.checkLimits(h, offset, depth); err != nil {
+		return offset, err
+	}
+
+	start := offset + h.Size
+	end := start + h.Payload()
+	if end > len(data) {
+		return offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(h.Code) + " payload"}
+	}
+
+	// User external, the other objects are decoded as usual
+	if handler, ok := d.extUserHandlers[v.Type()]; ok && h.Kind == Ext && h.ExtType == handler.Type && v.CanAddr() {
+		return d.decodeExt(data, offset, h, handler, v)
+	}
+
+	if h.Kind == Nil {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return end, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.decode(data, offset, v.Elem(), depth)
+
+	case reflect.Interface:
+		// Decode into the value pointed to by a non-nil pointer, like encoding/json
+		if !v.IsNil() && v.Elem().Kind() == reflect.Ptr && !v.Elem().IsNil() {
+			return d.decode(data, offset, v.Elem(), depth)
+		}
+		if v.NumMethod() > 0 {
+			return offset, typeError(h, v.Type(), offset)
+		}
+
+		value, next, err := d.decodeInterface(data, offset, depth)
+		if err != nil {
+			return next, err
+		}
+		if value == nil {
+			v.Set(reflect.Zero(v.Type()))
+		} else {
+			v.Set(reflect.ValueOf(value))
+		}
+		return next, nil
+	}
+
+	// Reserved external, or the other formats of the time policies
+	if v.Type() == timeType {
+		return d.decodeTime(data, offset, start, end, h, v)
+	}
+
+	switch h.Kind {
+	case Boolean:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(h.Code == types.True)
+			return end, nil
+		}
+
+	case Int:
+		i := ReadInt(data, offset, h)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if !v.OverflowInt(i) {
+				v.SetInt(i)
+				return end, nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if i >= 0 && !v.OverflowUint(uint64(i)) {
+				v.SetUint(uint64(i))
+				return end, nil
+			}
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(i))
+			return end, nil
+		}
+		return offset, utils.UnmarshalTypeError{Value: "int " + strconv.FormatInt(i, 10), Type: v.Type(), Offset: offset}
+
+	case Uint:
+		u := ReadUint(data, offset, h)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if u <= math.MaxInt64 && !v.OverflowInt(int64(u)) {
+				v.SetInt(int64(u))
+				return end, nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if !v.OverflowUint(u) {
+				v.SetUint(u)
+				return end, nil
+			}
+		case reflect.Float32, reflect.Float64:
+			v.SetFloat(float64(u))
+			return end, nil
+		}
+		return offset, utils.UnmarshalTypeError{Value: "uint " + strconv.FormatUint(u, 10), Type: v.Type(), Offset: offset}
+
+	case Float:
+		f := ReadFloat(data, offset, h)
+		if v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64 {
+			// Infinity is a valid float32 too
+			if math.IsInf(f, 0) || !v.OverflowFloat(f) {
+				v.SetFloat(f)
+				return end, nil
+			}
+			return offset, utils.UnmarshalTypeError{Value: "float " + strconv.FormatFloat(f, 'g', -1, 64), Type: v.Type(), Offset: offset}
+		}
+
+	case String:
+		if v.Kind() == reflect.String {
+			v.SetString(string(data[start:end]))
+			return end, nil
+		}
+		if d.CompatMode {
+			return d.bytes(data, offset, start, end, h, v)
+		}
+
+	case Binary:
+		return d.bytes(data, offset, start, end, h, v)
+
+	case Array, Map:
+		return d.container(data, offset, h, v, depth)
+	}
+
+	return offset, typeError(h, v.Type(), offset)
+}
+
+// bytes decodes the payload data[start:end] of the object that starts at data[offset]
+// into a byte slice or a byte array.
+func (d *DecoderState) bytes(data []byte, offset int, start int, end int, h Header, v reflect.Value) (int, error) {
+	switch {
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(d.Bytes(data[start:end]))
+		return end, nil
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+		// Like the arrays, the extra bytes are discarded and the missing ones are zero
+		for i := 0; i < v.Len(); i++ {
+			var b byte
+			if start+i < end {
+				b = data[start+i]
+			}
+			v.Index(i).SetUint(uint64(b))
+		}
+		return end, nil
+	}
+	return offset, typeError(h, v.Type(), offset)
+}
+
+// decodeTime decodes the object that starts at data[offset], with payload data[start:end],
+// into the time.Time v. It accepts a timestamp, a time with its zone, an integer in the unit of the time policy,
+// a floating point number of seconds and an RFC 3339 string.
+func (d *DecoderState) decodeTime(data []byte, offset int, start int, end int, h Header, v reflect.Value) (int, error) {
+	var t time.Time
+	switch h.Kind {
+	case Ext:
+		var err error
+		switch h.ExtType {
+		case -1:
+			t, err = ReadTimestamp(data[start:end])
+		case d.ZonedTimeType:
+			t, err = ReadZonedTime(data[start:end])
+		default:
+			return offset, typeError(h, v.Type(), offset)
+		}
+		if err != nil {
+			return offset, err
+		}
+
+	case Int, Uint:
+		var i int64
+		if h.Kind == Int {
+			i = ReadInt(data, offset, h)
+		} else if u := ReadUint(data, offset, h); u <= math.MaxInt64 {
+			i = int64(u)
+		} else {
+			return offset, utils.UnmarshalTypeError{Value: "uint " + strconv.FormatUint(u, 10), Type: v.Type(), Offset: offset}
+		}
+
+		switch d.Time {
+		case encode.TimeUnixMilli:
+			t = time.UnixMilli(i).UTC()
+		case encode.TimeUnixNano:
+			t = time.Unix(0, i).UTC()
+		default:
+			t = time.Unix(i, 0).UTC()
+		}
+
+	case Float:
+		f := ReadFloat(data, offset, h)
+		seconds := math.Floor(f)
+		if math.IsNaN(f) || seconds < math.MinInt64 || seconds >= math.MaxInt64 {
+			return offset, utils.UnmarshalTypeError{Value: "float " + strconv.FormatFloat(f, 'g', -1, 64), Type: v.Type(), Offset: offset}
+		}
+		t = time.Unix(int64(seconds), int64(math.Round((f-seconds)*1e9))).UTC()
+
+	case String:
+		s := string(data[start:end])
+		var err error
+		if t, err = time.Parse(time.RFC3339Nano, s); err != nil {
+			return offset, utils.UnmarshalTypeError{Value: "str " + strconv.Quote(s), Type: v.Type(), Offset: offset}
+		}
+
+	default:
+		return offset, typeError(h, v.Type(), offset)
+	}
+
+	v.Set(reflect.ValueOf(t))
+	return end, nil
+}
+
+// SetExternalTypeHandler associate a specific data type with a custom decoding
+// function provided by the user. A pointer type is associated as the type it points to,
+// and it is decoded allocating the value if needed.
+// Code is a number between 0 and 127 and indicate the correspondent MessagePack
+// External type code.
+func (d *DecoderState) SetExternalTypeHandler(typeInvolved interface{}, handler ExtUserHandler) error {
+	if handler.Type < 0 {
+		return utils.OutOfBoundError{Key: int(handler.Type)}
+	}
+	if handler.Decoder == nil && handler.Reader == nil {
+		return utils.InvalidTypeError{Type: "nil as function"}
+	}
+
+	t := reflect.TypeOf(typeInvolved)
+	if t == nil {
+		return utils.InvalidTypeError{Type: "nil as external type"}
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// The map is copied, because the copies of d share it
+	handlers := make(map[reflect.Type]ExtUserHandler, len(d.extUserHandlers)+1)
+	for k, v := range d.extUserHandlers {
+		handlers[k] = v
+	}
+	handlers[t] = handler
+	d.extUserHandlers = handlers
+	return nil
+}
+
+// Copy returns a copy of d with the same options, that can be used at the same time as d.
+func (d *DecoderState) Copy() *DecoderState {
+	c := *d
+	c.keys = nil
+	return &c
+}
+
+// checkLimits checks the length of the object with header h that starts at data[offset],
+// and the nesting depth of the elements of an array or a map.
+func (d *DecoderState) checkLimits(h Header, offset int, depth int) error {
+	if d.MaxLength > 0 && h.Length > d.MaxLength {
+		return utils.SyntaxError{Offset: offset, Desc: "exceeded max length"}
+	}
+
+	maxDepth := MaxDepth
+	if d.MaxDepth > 0 && d.MaxDepth < MaxDepth {
+		maxDepth = d.MaxDepth
+	}
+	if (h.Kind == Array || h.Kind == Map) && depth >= maxDepth {
+		return utils.SyntaxError{Offset: offset, Desc: "exceeded max nesting depth"}
+	}
+	return nil
+}
+
+// decodeExt decodes the external type with header h that starts at data[offset]
+// into v, using the handler of the user.
+func (d *DecoderState) decodeExt(data []byte, offset int, h Header, handler ExtUserHandler, v reflect.Value) (int, error) {
+	start := offset + h.Size
+	end := start + h.Payload()
+
+	var err error
+	if handler.Decoder != nil {
+		err = handler.Decoder(d.Bytes(data[start:end]), v.Addr().Interface())
+	} else {
+		err = handler.Reader(bytes.NewReader(data[start:end]), end-start, v.Addr().Interface())
+	}
+	if err != nil {
+		return offset, err
+	}
+	return end, nil
+}
+
+// Bytes returns b, or a copy of it if NoCopy isn't set. The capacity of the
+// result is its length, so an append never overwrites the following data.
+func (d *DecoderState) Bytes(b []byte) []byte {
+	if d.NoCopy {
+		return b[:len(b):len(b)]
+	}
+	return append([]byte(nil), b...)
+}
+
+// stringKey returns the string map key that starts at data[offset] and the offset of
+// the first byte after it, if the object is a string. The key is a string also in
+// compatibility mode, and it is interned if InternKeys is set.
+func (d *DecoderState) stringKey(data []byte, offset int) (string, int, bool) {
+	h, err := ReadHeader(data, offset)
+	if err != nil || h.Kind != String {
+		return "", offset, false
+	}
+	start := offset + h.Size
+	end := start + h.Payload()
+	if end > len(data) {
+		return "", offset, false
+	}
+
+	key := data[start:end]
+	if !d.InternKeys {
+		return string(key), end, true
+	}
+	if s, ok := d.keys[string(key)]; ok {
+		return s, end, true
+	}
+
+	s := string(key)
+	if d.keys == nil {
+		d.keys = make(map[string]string)
+	}
+	if len(d.keys) < maxInternedKeys {
+		d.keys[s] = s
+	}
+	return s, end, true
+}
+
+// mapKey decodes the map key that starts at data[offset] into key, using stringKey for the strings.
+func (d *DecoderState) mapKey(data []byte, offset int, key reflect.Value, depth int) (int, error) {
+	switch {
+	case key.Kind() == reflect.String:
+		if s, end, ok := d.stringKey(data, offset); ok {
+			key.SetString(s)
+			return end, nil
+		}
+	case key.Type() == interfaceType:
+		if s, end, ok := d.stringKey(data, offset); ok {
+			key.Set(reflect.ValueOf(s))
+			return end, nil
+		}
+	}
+	return d.decode(data, offset, key, depth)
+}
+
+// container decodes the array or the map that starts at data[offset] into v.
+func (d *DecoderState) container(data []byte, offset int, h Header, v reflect.Value, depth int) (int, error) {
+	next := offset + h.Size
+
+	// Every element is at least one byte long, don't trust a bigger length
+	if h.Elements() > len(data)-next {
+		return offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(h.Code) + " elements"}
+	}
+
+	var err error
+	if h.Kind == Array {
+		switch v.Kind() {
+		case reflect.Slice:
+			slice := reflect.MakeSlice(v.Type(), h.Length, h.Length)
+			for i := 0; i < h.Length; i++ {
+				if next, err = d.decode(data, next, slice.Index(i), depth+1); err != nil {
+					return next, err
+				}
+			}
+			v.Set(slice)
+			return next, nil
+
+		case reflect.Array:
+			// The extra elements are discarded, and the missing ones are set to zero
+			for i := 0; i < h.Length; i++ {
+				if i < v.Len() {
+					next, err = d.decode(data, next, v.Index(i), depth+1)
+				} else {
+					next, err = Skip(data, next)
+				}
+				if err != nil {
+					return next, err
+				}
+			}
+			for i := h.Length; i < v.Len(); i++ {
+				v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+			}
+			return next, nil
+
+		case reflect.Struct:
+			return d.structArray(data, offset, next, h, v, depth)
+		}
+		return offset, typeError(h, v.Type(), offset)
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		mapType := v.Type()
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(mapType, h.Length))
+		}
+
+		// Keys of this map, to find the duplicated ones
+		var seen map[interface{}]struct{}
+		if d.DuplicateKeys == DuplicateError || d.DuplicateKeys == DuplicateFirst {
+			seen = make(map[interface{}]struct{}, h.Length)
+		}
+
+		for i := 0; i < h.Length; i++ {
+			keyOffset := next
+			key := reflect.New(mapType.Key()).Elem()
+			if next, err = d.mapKey(data, next, key, depth+1); err != nil {
+				return next, err
+			}
+			if !hashable(key) {
+				return keyOffset, utils.UnmarshalTypeError{Value: "unhashable map key", Type: mapType.Key(), Offset: keyOffset}
+			}
+
+			if seen != nil {
+				if _, already := seen[key.Interface()]; already {
+					if d.DuplicateKeys == DuplicateError {
+						return keyOffset, utils.DuplicatedKeyError{Key: key.Interface(), Offset: keyOffset}
+					}
+					// The first value wins
+					if next, err = Skip(data, next); err != nil {
+						return next, err
+					}
+					continue
+				}
+				seen[key.Interface()] = struct{}{}
+			}
+
+			value := reflect.New(mapType.Elem()).Elem()
+			if next, err = d.decode(data, next, value, depth+1); err != nil {
+				return next, err
+			}
+			v.SetMapIndex(key, value)
+		}
+		return next, nil
+
+	case reflect.Struct:
+		return d.structMap(data, offset, next, h, v, depth)
+	}
+	return offset, typeError(h, v.Type(), offset)
+}
+
+// hashable reports whether the decoded map key v can be stored in a Go map. The key types are
+// comparable, but the arrays and the structs can contain interfaces, whose values must be too.
+func hashable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		return v.IsNil() || hashable(v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !hashable(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !hashable(v.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Slice, reflect.Map, reflect.Func:
+		return false
+	}
+	return true
+}
+
+// decodeInterface decodes the object that starts at data[offset] into the
+// Go value that represents it best, when the target is an empty interface.
+func (d *DecoderState) decodeInterface(data []byte, offset int, depth int) (interface{}, int, error) {
+	h, err := ReadHeader(data, offset)
+	if err != nil {
+		return nil, offset, err
+	}
+	if err = d.checkLimits(h, offset, depth); err != nil {
+		return nil, offset, err
+	}
+
+	start := offset + h.Size
+	end := start + h.Payload()
+	if end > len(data) {
+		return nil, offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(h.Code) + " payload"}
+	}
+
+	switch h.Kind {
+	case Nil:
+		return nil, end, nil
+	case Boolean:
+		return h.Code == types.True, end, nil
+	case Int:
+		return ReadInt(data, offset, h), end, nil
+	case Uint:
+		return ReadUint(data, offset, h), end, nil
+	case Float:
+		return ReadFloat(data, offset, h), end, nil
+	case String:
+		if d.CompatMode {
+			return d.Bytes(data[start:end]), end, nil
+		}
+		return string(data[start:end]), end, nil
+	case Binary:
+		return d.Bytes(data[start:end]), end, nil
+	case Ext:
+		if h.ExtType != -1 {
+			return nil, offset, typeError(h, interfaceType, offset)
+		}
+		t, err := ReadTimestamp(data[start:end])
+		if err != nil {
+			return nil, offset, err
+		}
+		return t, end, nil
+	}
+
+	if h.Elements() > len(data)-end {
+		return nil, offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(h.Code) + " elements"}
+	}
+
+	if h.Kind == Array {
+		array := make([]interface{}, h.Length)
+		for i := range array {
+			if array[i], end, err = d.decodeInterface(data, end, depth+1); err != nil {
+				return nil, end, err
+			}
+		}
+		return array, end, nil
+	}
+
+	// The map has string keys only if all its keys are strings
+	keys := make([]interface{}, 0, h.Length)
+	values := make([]interface{}, 0, h.Length)
+	stringKeys := true
+
+	// Keys of this map, to find the duplicated ones
+	var seen map[interface{}]struct{}
+	if d.DuplicateKeys == DuplicateError || d.DuplicateKeys == DuplicateFirst {
+		seen = make(map[interface{}]struct{}, h.Length)
+	}
+
+	for i := 0; i < h.Length; i++ {
+		keyOffset := end
+		var key, value interface{}
+		if s, next, ok := d.stringKey(data, end); ok {
+			key, end = s, next
+		} else if key, end, err = d.decodeInterface(data, end, depth+1); err != nil {
+			return nil, end, err
+		}
+		if _, ok := key.(string); !ok {
+			stringKeys = false
+			if key != nil && !reflect.TypeOf(key).Comparable() {
+				return nil, keyOffset, utils.UnmarshalTypeError{Value: "unhashable map key", Type: interfaceType, Offset: keyOffset}
+			}
+		}
+
+		if seen != nil {
+			if _, already := seen[key]; already {
+				if d.DuplicateKeys == DuplicateError {
+					return nil, keyOffset, utils.DuplicatedKeyError{Key: key, Offset: keyOffset}
+				}
+				// The first value wins
+				if end, err = Skip(data, end); err != nil {
+					return nil, end, err
+				}
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+
+		if value, end, err = d.decodeInterface(data, end, depth+1); err != nil {
+			return nil, end, err
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+
+	if stringKeys {
+		m := make(map[string]interface{}, h.Length)
+		for i := range keys {
+			m[keys[i].(string)] = values[i]
+		}
+		return m, end, nil
+	}
+
+	m := make(map[interface{}]interface{}, h.Length)
+	for i := range keys {
+		m[keys[i]] = values[i]
+	}
+	return m, end, nil
+}
+
+// structField is an exported struct field that takes part in the decoding.
+type structField struct {
+	index   int    // Index of the field in the struct
+	name    string // Name in the tag, or Go field name
+	options utils.Options
+}
+
+// structFields returns the fields of t that take part in the decoding, and whether t
+// is encoded as an array, with the same rules used by the encoder.
+func (d *DecoderState) structFields(t reflect.Type) (fields []structField, asArray bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tagValue, _ := utils.LookupTag(field.Tag, d.TagNames)
+		tagName, tagOptions := utils.ParseTag(tagValue)
+
+		if tagName == "-" && len(tagValue) == 1 {
+			// Skip "-"
+			continue
+		}
+
+		if tagName == "" {
+			tagName = field.Name
+		}
+
+		if tagOptions.Contains("structarray") {
+			asArray = true
+		}
+
+		fields = append(fields, structField{index: i, name: tagName, options: tagOptions})
+	}
+	return
+}
+
+// structArray decodes the array that starts at data[offset] into the struct v,
+// that must have the structarray option, assigning the elements to the fields in order.
+func (d *DecoderState) structArray(data []byte, offset int, next int, h Header, v reflect.Value, depth int) (int, error) {
+	fields, asArray := d.structFields(v.Type())
+	if !asArray {
+		return offset, typeError(h, v.Type(), offset)
+	}
+
+	if d.DisallowUnknownFields {
+		count := 0
+		for _, field := range fields {
+			if !field.options.Contains("setcustomkeys") {
+				count++
+			}
+		}
+		if h.Length != count {
+			return offset, utils.UnmarshalTypeError{Value: "array of " + strconv.Itoa(h.Length) + " elements", Type: v.Type(), Offset: offset}
+		}
+	}
+
+	var err error
+	i := 0
+	for _, field := range fields {
+		if field.options.Contains("setcustomkeys") {
+			continue
+		}
+		if i == h.Length {
+			// The array is shorter
+			if field.options.Contains("required") {
+				return offset, utils.MissingFieldError{Field: field.name, Type: v.Type(), Offset: offset}
+			}
+			continue
+		}
+		if next, err = d.decodeField(data, next, v, field, depth); err != nil {
+			return next, err
+		}
+		i++
+	}
+
+	// Discard the elements without a field
+	for ; i < h.Length; i++ {
+		if next, err = Skip(data, next); err != nil {
+			return next, err
+		}
+	}
+	return next, nil
+}
+
+// structMap decodes the map that starts at data[offset] into the struct v, matching its
+// keys with the names of the fields or with the custom keys. Unknown keys are ignored,
+// unless DisallowUnknownFields is set.
+func (d *DecoderState) structMap(data []byte, offset int, next int, h Header, v reflect.Value, depth int) (int, error) {
+	fields, asArray := d.structFields(v.Type())
+	if asArray {
+		return offset, typeError(h, v.Type(), offset)
+	}
+
+	// Encoded custom keys, compared with the keys of the map as they are
+	type customKey struct {
+		field int
+		key   []byte
+	}
+
+	names := make(map[string]int, len(fields))
+	var customKeys []customKey
+	var customKeysMap reflect.Value
+
+	for i, field := range fields {
+		switch {
+		case field.options.Contains("setcustomkeys"):
+			customKeysMap = v.Field(field.index)
+		case field.options.Contains("customkey"):
+			key, err := d.customKey(customKeysMap, field.name)
+			if err != nil {
+				return offset, err
+			}
+			customKeys = append(customKeys, customKey{field: i, key: key})
+		default:
+			if _, already := names[field.name]; already {
+				return offset, utils.DuplicatedKeyError{Key: field.name}
+			}
+			names[field.name] = i
+		}
+	}
+
+	// Fields already decoded, to find the duplicated keys and the missing required fields
+	var seen []bool
+	required := false
+	for _, field := range fields {
+		required = required || field.options.Contains("required")
+	}
+	if d.DuplicateKeys != DuplicateAllow || required {
+		seen = make([]bool, len(fields))
+	}
+
+	var err error
+	for i := 0; i < h.Length; i++ {
+		keyOffset := next
+		if next, err = Skip(data, next); err != nil {
+			return next, err
+		}
+		key := data[keyOffset:next]
+
+		field := -1
+		if keyHeader, _ := ReadHeader(key, 0); keyHeader.Kind == String {
+			if j, ok := names[string(key[keyHeader.Size:])]; ok {
+				field = j
+			}
+		}
+		for j := 0; field < 0 && j < len(customKeys); j++ {
+			if bytes.Equal(key, customKeys[j].key) {
+				field = customKeys[j].field
+			}
+		}
+
+		if field < 0 && d.DisallowUnknownFields {
+			keyValue, _, _ := d.decodeInterface(key, 0, depth+1)
+			return keyOffset, utils.UnknownFieldError{Key: keyValue, Type: v.Type(), Offset: keyOffset}
+		}
+
+		if field >= 0 && seen != nil {
+			if seen[field] {
+				switch d.DuplicateKeys {
+				case DuplicateError:
+					keyValue, _, _ := d.decodeInterface(key, 0, depth+1)
+					return keyOffset, utils.DuplicatedKeyError{Key: keyValue, Offset: keyOffset}
+				case DuplicateFirst:
+					field = -1
+				case DuplicateLast:
+					fieldValue := v.Field(fields[field].index)
+					fieldValue.Set(reflect.Zero(fieldValue.Type()))
+				}
+			} else {
+				seen[field] = true
+			}
+		}
+
+		if field < 0 {
+			next, err = Skip(data, next)
+		} else {
+			next, err = d.decodeField(data, next, v, fields[field], depth)
+		}
+		if err != nil {
+			return next, err
+		}
+	}
+
+	if required {
+		for i, field := range fields {
+			if !seen[i] && field.options.Contains("required") {
+				return offset, utils.MissingFieldError{Field: field.name, Type: v.Type(), Offset: offset}
+			}
+		}
+	}
+	return next, nil
+}
+
+// decodeField decodes the object that starts at data[offset] into the field of the struct v.
+func (d *DecoderState) decodeField(data []byte, offset int, v reflect.Value, field structField, depth int) (int, error) {
+	fieldValue := v.Field(field.index)
+	if !field.options.Contains("string") || !utils.Quotable(fieldValue.Type()) {
+		// The time option of the tag applies to all the times in the field
+		policy, err := d.Time.WithOptions(field.options)
+		if err != nil {
+			return offset, err
+		}
+		if policy != d.Time {
+			fieldState := *d
+			fieldState.Time = policy
+			return fieldState.decode(data, offset, fieldValue, depth+1)
+		}
+		return d.decode(data, offset, fieldValue, depth+1)
+	}
+
+	// Booleans and numbers in their string form
+	h, err := ReadHeader(data, offset)
+	if err != nil {
+		return offset, err
+	}
+	if h.Kind == Nil {
+		return d.decode(data, offset, fieldValue, depth+1)
+	}
+	if h.Kind != String {
+		return offset, typeError(h, fieldValue.Type(), offset)
+	}
+
+	start := offset + h.Size
+	end := start + h.Length
+	if end > len(data) {
+		return offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(h.Code) + " payload"}
+	}
+
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		}
+		fieldValue = fieldValue.Elem()
+	}
+
+	s := string(data[start:end])
+	switch fieldValue.Kind() {
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			fieldValue.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, fieldValue.Type().Bits()); err == nil {
+			fieldValue.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, fieldValue.Type().Bits()); err == nil {
+			fieldValue.SetUint(u)
+		}
+	default:
+		var f float64
+		if f, err = strconv.ParseFloat(s, fieldValue.Type().Bits()); err == nil {
+			fieldValue.SetFloat(f)
+		}
+	}
+
+	if err != nil {
+		return offset, utils.UnmarshalTypeError{Value: "str " + strconv.Quote(s), Type: fieldValue.Type(), Offset: offset}
+	}
+	return end, nil
+}
+
+// customKey returns the MessagePack encoding of the custom key associated with name
+// in the map of the setcustomkeys field, like the encoder does.
+func (d *DecoderState) customKey(customKeysMap reflect.Value, name string) ([]byte, error) {
+	if !customKeysMap.IsValid() {
+		return nil, utils.InvalidTypeError{Type: "invalid key " + name + " using customkey option"}
+	}
+	if customKeysMap.Kind() != reflect.Map || customKeysMap.Type().Key().Kind() != reflect.String {
+		return nil, utils.InvalidTypeError{Type: "invalid custom keys type"}
+	}
+
+	key := customKeysMap.MapIndex(reflect.ValueOf(name).Convert(customKeysMap.Type().Key()))
+	if !key.IsValid() {
+		return nil, utils.InvalidTypeError{Type: "invalid key " + name + " using customkey option"}
+	}
+
+	state := encode.NewEncoderState()
+	state.TagNames = d.TagNames
+	state.CompatMode = d.CompatMode
+	encoded := state.TypeWrapper(reflect.ValueOf(key.Interface()))
+
+	var b bytes.Buffer
+	_, err := encoded.WriteTo(&b)
+	return b.Bytes(), err
+}
+
+// typeError returns the error for an object that can't be stored in a Go value of type t.
+func typeError(h Header, t reflect.Type, offset int) error {
+	value := h.Kind.String()
+	if h.Kind == Ext {
+		value += " " + strconv.Itoa(int(h.ExtType))
+	}
+	return utils.UnmarshalTypeError{Value: value, Type: t, Offset: offset}
+}
@@ -0,0 +1,136 @@
+package decode
+
+import (
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
+	"testing"
+)
+
+func TestDecoderState_Decode_TagNames(t *testing.T) {
+	type Test struct {
+		A int `sbor:"a" json:"x"`
+		B int `json:"b"`
+		C int `msgpack:"-" json:"c"`
+	}
+
+	// {"a": 1, "b": 2, "c": 3, "x": 4}
+	data := []byte{0x84, 0xA1, 0x61, 0x01, 0xA1, 0x62, 0x02, 0xA1, 0x63, 0x03, 0xA1, 0x78, 0x04}
+
+	tests := []struct {
+		names    []string
+		expected Test
+	}{
+		{names: []string{"sbor"}, expected: Test{A: 1}},
+		{names: []string{"json"}, expected: Test{A: 4, B: 2, C: 3}},
+		{names: []string{"sbor", "msgpack", "json"}, expected: Test{A: 1, B: 2}},
+	}
+
+	for _, test := range tests {
+		d := NewDecoderState()
+		d.TagNames = test.names
+
+		var result Test
+		end, err := d.Decode(data, 0, reflect.ValueOf(&result).Elem())
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if end != len(data) {
+			t.Errorf("Invalid end offset. Function returned %d. Expected %d.", end, len(data))
+		}
+		if result != test.expected {
+			t.Errorf("Invalid result with %v. Function returned %+v. Expected %+v.", test.names, result, test.expected)
+		}
+	}
+}
+
+func TestDecoderState_Decode_Error(t *testing.T) {
+	var result []interface{}
+	d := NewDecoderState()
+
+	// Truncated array, and nested arrays beyond the max depth
+	for _, data := range [][]byte{{0x92, 0x01}, deepArray(MaxDepth + 1)} {
+		if _, err := d.Decode(data, 0, reflect.ValueOf(&result).Elem()); err == nil {
+			t.Error("Error was expected.")
+		} else if _, ok := err.(utils.SyntaxError); !ok {
+			t.Errorf("SyntaxError was expected. Function returned %v.", err)
+		}
+	}
+}
+
+// deepArray returns depth nested arrays with one element.
+func deepArray(depth int) []byte {
+	data := make([]byte, depth+1)
+	for i := 0; i < depth; i++ {
+		data[i] = 0x91
+	}
+	data[depth] = 0xC0
+	return data
+}
+
+func TestDecoderState_Decode_InternKeys(t *testing.T) {
+	// [{"a": 1}, {"a": 2}, {1: 3}]
+	data := []byte{0x93, 0x81, 0xA1, 0x61, 0x01, 0x81, 0xA1, 0x61, 0x02, 0x81, 0x01, 0x03}
+
+	d := NewDecoderState()
+	d.InternKeys = true
+
+	var result []map[interface{}]int
+	if _, err := d.Decode(data, 0, reflect.ValueOf(&result).Elem()); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []map[interface{}]int{{"a": 1}, {"a": 2}, {uint64(1): 3}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", result, expected)
+	}
+	if len(d.keys) != 1 || d.keys["a"] != "a" {
+		t.Errorf("Invalid interned keys. Function returned %v.", d.keys)
+	}
+
+	// The interned keys are limited
+	for i := 0; i < maxInternedKeys+10; i++ {
+		d.stringKey([]byte{0xA4, byte(i >> 24), byte(i >> 16), byte(i >> 8), byte(i)}, 0)
+	}
+	if len(d.keys) != maxInternedKeys {
+		t.Errorf("Invalid number of interned keys. Function returned %d. Expected %d.", len(d.keys), maxInternedKeys)
+	}
+}
+
+func TestDecoderState_SetExternalTypeHandler(t *testing.T) {
+	decoder := func(data []byte, v interface{}) error {
+		*v.(*[]byte) = data
+		return nil
+	}
+
+	d := NewDecoderState()
+	copied := d.Copy()
+	if err := d.SetExternalTypeHandler(new([]byte), ExtUserHandler{Type: 5, Decoder: decoder}); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// ext(5, h'0102')
+	data := []byte{0xD5, 0x05, 0x01, 0x02}
+	var result []byte
+	if _, err := d.Decode(data, 0, reflect.ValueOf(&result).Elem()); err != nil || !reflect.DeepEqual(result, []byte{0x01, 0x02}) {
+		t.Errorf("Invalid result. Function returned %v, %v.", result, err)
+	}
+
+	// The copy made before the registration is unchanged
+	if _, err := copied.Decode(data, 0, reflect.ValueOf(&result).Elem()); err == nil {
+		t.Error("Error was expected with the copy.")
+	}
+
+	invalid := []struct {
+		value   interface{}
+		handler ExtUserHandler
+	}{
+		{value: 0, handler: ExtUserHandler{Type: -1, Decoder: decoder}},
+		{value: 0, handler: ExtUserHandler{Type: 1}},
+		{value: nil, handler: ExtUserHandler{Type: 1, Decoder: decoder}},
+	}
+	for _, test := range invalid {
+		if err := d.SetExternalTypeHandler(test.value, test.handler); err == nil {
+			t.Errorf("Error was expected with %+v.", test)
+		}
+	}
+}
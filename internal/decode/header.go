@@ -0,0 +1,286 @@
+package decode
+
+import (
+	"encoding/binary"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is the family of a MessagePack type.
+type Kind uint8
+
+// MessagePack type families
+const (
+	Invalid Kind = iota
+	Nil
+	Boolean
+	Int
+	Uint
+	Float
+	String
+	Binary
+	Array
+	Map
+	Ext
+)
+
+var kindNames = [...]string{
+	Invalid: "invalid",
+	Nil:     "nil",
+	Boolean: "bool",
+	Int:     "int",
+	Uint:    "uint",
+	Float:   "float",
+	String:  "str",
+	Binary:  "bin",
+	Array:   "array",
+	Map:     "map",
+	Ext:     "ext",
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return kindNames[Invalid]
+}
+
+// Header is the decoded header of a MessagePack object.
+type Header struct {
+	Kind    Kind
+	Code    byte // First byte of the object, that identifies its format
+	Size    int  // Length of the header in bytes, ext type included
+	Length  int  // Number of elements for array and map, length of the payload for the other kinds
+	ExtType int8
+}
+
+// Elements returns the number of objects that follow the header.
+func (h Header) Elements() int {
+	switch h.Kind {
+	case Array:
+		return h.Length
+	case Map:
+		return 2 * h.Length
+	default:
+		return 0
+	}
+}
+
+// Payload returns the number of bytes that follow the header, excluding nested objects.
+func (h Header) Payload() int {
+	if h.Kind == Array || h.Kind == Map {
+		return 0
+	}
+	return h.Length
+}
+
+// ReadHeader decodes the header of the object that starts at data[offset].
+// It checks that the header is complete, but it doesn't check the payload.
+func ReadHeader(data []byte, offset int) (Header, error) {
+	if offset >= len(data) {
+		return Header{}, utils.SyntaxError{Offset: offset, Desc: "unexpected end of data"}
+	}
+
+	code := data[offset]
+	h := Header{Code: code, Size: 1}
+
+	switch {
+	case code <= 0x7F:
+		h.Kind = Uint
+		return h, nil
+	case code >= 0xE0:
+		h.Kind = Int
+		return h, nil
+	case code&0xF0 == types.FixMap:
+		h.Kind, h.Length = Map, int(code&0x0F)
+		return h, nil
+	case code&0xF0 == types.FixArray:
+		h.Kind, h.Length = Array, int(code&0x0F)
+		return h, nil
+	case code&0xE0 == types.FixStr:
+		h.Kind, h.Length = String, int(code&types.Max5Bit)
+		return h, nil
+	}
+
+	// lengthSize is the size of the length field that follows the code,
+	// fixed is the payload length of the formats without length field.
+	var lengthSize, fixed int
+	var ext bool
+
+	switch code {
+	case types.NilCode:
+		h.Kind = Nil
+	case types.False, types.True:
+		h.Kind = Boolean
+	case types.Bin8, types.Bin16, types.Bin32:
+		h.Kind, lengthSize = Binary, 1<<(code-types.Bin8)
+	case types.Ext8, types.Ext16, types.Ext32:
+		h.Kind, lengthSize, ext = Ext, 1<<(code-types.Ext8), true
+	case types.Float32:
+		h.Kind, fixed = Float, 4
+	case types.Float64:
+		h.Kind, fixed = Float, 8
+	case types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		h.Kind, fixed = Uint, 1<<(code-types.Uint8)
+	case types.Int8, types.Int16, types.Int32, types.Int64:
+		h.Kind, fixed = Int, 1<<(code-types.Int8)
+	case types.FixExt1, types.FixExt2, types.FixExt4, types.FixExt8, types.FixExt16:
+		h.Kind, fixed, ext = Ext, 1<<(code-types.FixExt1), true
+	case types.Str8, types.Str16, types.Str32:
+		h.Kind, lengthSize = String, 1<<(code-types.Str8)
+	case types.Array16, types.Array32:
+		h.Kind, lengthSize = Array, 2<<(code-types.Array16)
+	case types.Map16, types.Map32:
+		h.Kind, lengthSize = Map, 2<<(code-types.Map16)
+	default:
+		return Header{}, utils.SyntaxError{Offset: offset, Desc: "reserved byte 0xc1"}
+	}
+
+	h.Size += lengthSize
+	if ext {
+		h.Size++
+	}
+	if offset+h.Size > len(data) {
+		return Header{}, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(code) + " header"}
+	}
+
+	switch lengthSize {
+	case 1:
+		h.Length = int(data[offset+1])
+	case 2:
+		h.Length = int(binary.BigEndian.Uint16(data[offset+1:]))
+	case 4:
+		h.Length = int(binary.BigEndian.Uint32(data[offset+1:]))
+	default:
+		h.Length = fixed
+	}
+
+	if ext {
+		h.ExtType = int8(data[offset+h.Size-1])
+	}
+
+	return h, nil
+}
+
+// Skip returns the offset of the first byte after the object that starts at data[offset],
+// checking that the object and all its nested objects are complete.
+func Skip(data []byte, offset int) (int, error) {
+	return skip(data, offset, false)
+}
+
+// SkipValid works like Skip, but it also checks that the payloads
+// of all the strings are valid UTF-8.
+func SkipValid(data []byte, offset int) (int, error) {
+	return skip(data, offset, true)
+}
+
+func skip(data []byte, offset int, checkUTF8 bool) (int, error) {
+	// Number of objects that still have to be skipped
+	remaining := 1
+
+	for remaining > 0 {
+		h, err := ReadHeader(data, offset)
+		if err != nil {
+			return offset, err
+		}
+
+		remaining += h.Elements() - 1
+		end := offset + h.Size + h.Payload()
+		if end > len(data) || end < offset {
+			return offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(h.Code) + " payload"}
+		}
+
+		if checkUTF8 && h.Kind == String {
+			if i := invalidUTF8(data[offset+h.Size : end]); i >= 0 {
+				return offset, utils.SyntaxError{Offset: offset + h.Size + i, Desc: "invalid UTF-8 in " + FormatName(h.Code)}
+			}
+		}
+		offset = end
+
+		// Every object is at least one byte long
+		if remaining > len(data)-offset {
+			return offset, utils.SyntaxError{Offset: len(data), Desc: "unexpected end of data"}
+		}
+	}
+
+	return offset, nil
+}
+
+// IsTruncated reports whether err, returned by Skip, means that data ends before
+// the end of the object, so that more data could complete it.
+func IsTruncated(err error) bool {
+	s, ok := err.(utils.SyntaxError)
+	return ok && (s.Desc == "unexpected end of data" || strings.HasPrefix(s.Desc, "truncated "))
+}
+
+// invalidUTF8 returns the index of the first invalid UTF-8 byte in b, or -1 if b is valid.
+func invalidUTF8(b []byte) int {
+	if utf8.Valid(b) {
+		return -1
+	}
+
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
+
+// FormatName returns the name of the MessagePack format identified by the first byte of an object.
+func FormatName(code byte) string {
+	switch {
+	case code <= 0x7F:
+		return "positive fixint"
+	case code >= 0xE0:
+		return "negative fixint"
+	case code&0xF0 == types.FixMap:
+		return "fixmap"
+	case code&0xF0 == types.FixArray:
+		return "fixarray"
+	case code&0xE0 == types.FixStr:
+		return "fixstr"
+	}
+
+	return formatNames[code-types.NilCode]
+}
+
+var formatNames = [...]string{
+	types.NilCode - types.NilCode:  "nil",
+	0xC1 - types.NilCode:           "(never used)",
+	types.False - types.NilCode:    "false",
+	types.True - types.NilCode:     "true",
+	types.Bin8 - types.NilCode:     "bin8",
+	types.Bin16 - types.NilCode:    "bin16",
+	types.Bin32 - types.NilCode:    "bin32",
+	types.Ext8 - types.NilCode:     "ext8",
+	types.Ext16 - types.NilCode:    "ext16",
+	types.Ext32 - types.NilCode:    "ext32",
+	types.Float32 - types.NilCode:  "float32",
+	types.Float64 - types.NilCode:  "float64",
+	types.Uint8 - types.NilCode:    "uint8",
+	types.Uint16 - types.NilCode:   "uint16",
+	types.Uint32 - types.NilCode:   "uint32",
+	types.Uint64 - types.NilCode:   "uint64",
+	types.Int8 - types.NilCode:     "int8",
+	types.Int16 - types.NilCode:    "int16",
+	types.Int32 - types.NilCode:    "int32",
+	types.Int64 - types.NilCode:    "int64",
+	types.FixExt1 - types.NilCode:  "fixext1",
+	types.FixExt2 - types.NilCode:  "fixext2",
+	types.FixExt4 - types.NilCode:  "fixext4",
+	types.FixExt8 - types.NilCode:  "fixext8",
+	types.FixExt16 - types.NilCode: "fixext16",
+	types.Str8 - types.NilCode:     "str8",
+	types.Str16 - types.NilCode:    "str16",
+	types.Str32 - types.NilCode:    "str32",
+	types.Array16 - types.NilCode:  "array16",
+	types.Array32 - types.NilCode:  "array32",
+	types.Map16 - types.NilCode:    "map16",
+	types.Map32 - types.NilCode:    "map32",
+}
@@ -0,0 +1,127 @@
+package decode
+
+import (
+	"github.com/ErikPelli/sbor/internal/utils"
+	"testing"
+)
+
+func TestReadHeader(t *testing.T) {
+	data := []struct {
+		input    []byte
+		expected Header
+		name     string
+	}{
+		{input: []byte{0x05}, expected: Header{Kind: Uint, Code: 0x05, Size: 1}, name: "positive fixint"},
+		{input: []byte{0xFC}, expected: Header{Kind: Int, Code: 0xFC, Size: 1}, name: "negative fixint"},
+		{input: []byte{0x83}, expected: Header{Kind: Map, Code: 0x83, Size: 1, Length: 3}, name: "fixmap"},
+		{input: []byte{0x9F}, expected: Header{Kind: Array, Code: 0x9F, Size: 1, Length: 15}, name: "fixarray"},
+		{input: []byte{0xA7}, expected: Header{Kind: String, Code: 0xA7, Size: 1, Length: 7}, name: "fixstr"},
+		{input: []byte{0xC0}, expected: Header{Kind: Nil, Code: 0xC0, Size: 1}, name: "nil"},
+		{input: []byte{0xC3}, expected: Header{Kind: Boolean, Code: 0xC3, Size: 1}, name: "true"},
+		{input: []byte{0xC5, 0x03, 0xE8}, expected: Header{Kind: Binary, Code: 0xC5, Size: 3, Length: 1000}, name: "bin16"},
+		{input: []byte{0xC7, 0x64, 0x67}, expected: Header{Kind: Ext, Code: 0xC7, Size: 3, Length: 100, ExtType: 0x67}, name: "ext8"},
+		{input: []byte{0xCB}, expected: Header{Kind: Float, Code: 0xCB, Size: 1, Length: 8}, name: "float64"},
+		{input: []byte{0xCE}, expected: Header{Kind: Uint, Code: 0xCE, Size: 1, Length: 4}, name: "uint32"},
+		{input: []byte{0xD1}, expected: Header{Kind: Int, Code: 0xD1, Size: 1, Length: 2}, name: "int16"},
+		{input: []byte{0xD6, 0xFF}, expected: Header{Kind: Ext, Code: 0xD6, Size: 2, Length: 4, ExtType: -1}, name: "fixext4"},
+		{input: []byte{0xDB, 0x00, 0x01, 0x38, 0x80}, expected: Header{Kind: String, Code: 0xDB, Size: 5, Length: 80000}, name: "str32"},
+		{input: []byte{0xDC, 0x00, 0x10}, expected: Header{Kind: Array, Code: 0xDC, Size: 3, Length: 16}, name: "array16"},
+		{input: []byte{0xDF, 0x00, 0x01, 0x00, 0x00}, expected: Header{Kind: Map, Code: 0xDF, Size: 5, Length: 1 << 16}, name: "map32"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ReadHeader(test.input, 0)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			if result != test.expected {
+				t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, test.expected)
+			}
+
+			if name := FormatName(test.input[0]); name != test.name {
+				t.Errorf("Invalid format name. Function returned %s. Expected %s.", name, test.name)
+			}
+		})
+	}
+}
+
+func TestReadHeader_Error(t *testing.T) {
+	data := [][]byte{
+		{},
+		{0xC1},
+		{0xDA, 0x01},
+		{0xC8, 0x00, 0x01},
+	}
+
+	for _, test := range data {
+		if _, err := ReadHeader(test, 0); err == nil {
+			t.Errorf("Error was expected for %v.", test)
+		}
+	}
+}
+
+func TestSkip(t *testing.T) {
+	data := []struct {
+		input    []byte
+		expected int
+		name     string
+	}{
+		{input: []byte{0x01, 0x02}, expected: 1, name: "fixint"},
+		{input: []byte{0x92, 0xA3, 0x66, 0x6F, 0x6F, 0xA3, 0x62, 0x61, 0x72, 0xC0}, expected: 9, name: "array"},
+		{input: []byte{0x82, 0xF8, 0xCD, 0x7D, 0x00, 0x26, 0xD2, 0xFF, 0xFF, 0x63, 0xC0}, expected: 11, name: "map"},
+		{input: []byte{0x81, 0xA1, 0x69, 0x93, 0xF8, 0x91, 0xC0, 0xC2}, expected: 8, name: "nested"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Skip(test.input, 0)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			if result != test.expected {
+				t.Errorf("Invalid result. Function returned %d. Expected %d.", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestSkip_Error(t *testing.T) {
+	data := [][]byte{
+		{0x92, 0xC0},
+		{0xA3, 0x66, 0x6F},
+		{0xDD, 0xFF, 0xFF, 0xFF, 0xFF, 0xC0},
+		{0x81, 0xC1, 0xC0},
+	}
+
+	truncated := []bool{true, true, true, false}
+
+	for i, test := range data {
+		_, err := Skip(test, 0)
+		if err == nil {
+			t.Errorf("Error was expected for %v.", test)
+		}
+		if IsTruncated(err) != truncated[i] {
+			t.Errorf("Invalid IsTruncated result for %v. Expected %v.", test, truncated[i])
+		}
+	}
+}
+
+func TestSkipValid(t *testing.T) {
+	valid := []byte{0x92, 0xA3, 0x66, 0xC3, 0xA9, 0xC0}
+	if end, err := SkipValid(valid, 0); err != nil || end != len(valid) {
+		t.Errorf("Invalid result. Function returned %d, %v. Expected %d.", end, err, len(valid))
+	}
+
+	invalid := []byte{0x92, 0xC0, 0xA3, 0x66, 0xC3, 0x28}
+	if _, err := Skip(invalid, 0); err != nil {
+		t.Errorf("Skip must not check UTF-8. Error: %v", err)
+	}
+
+	_, err := SkipValid(invalid, 0)
+	if syntax, ok := err.(utils.SyntaxError); !ok || syntax.Offset != 4 {
+		t.Errorf("Invalid error. Function returned %v. Expected offset %d.", err, 4)
+	}
+}
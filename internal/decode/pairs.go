@@ -0,0 +1,105 @@
+package decode
+
+import (
+	"bytes"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
+)
+
+// DuplicatePolicy chooses how the duplicated keys of a map are handled.
+type DuplicatePolicy uint8
+
+const (
+	DuplicateAllow DuplicatePolicy = iota // Every entry is kept
+	DuplicateError                        // Error at the second entry with the same key
+	DuplicateFirst                        // Only the first entry with the same key is kept
+	DuplicateLast                         // Only the last entry with the same key is kept
+)
+
+// Pairs decodes the map that starts at data[offset] into the slice v, whose elements are structs
+// with the key as first field and the value as second field, keeping the order of the entries.
+// MessagePack nil sets v to nil. The map is at the given nesting depth, that is checked against
+// the limits like for the other maps. It returns the offset of the first byte after the map.
+func (d *DecoderState) Pairs(data []byte, offset int, v reflect.Value, depth int) (int, error) {
+	h, err := ReadHeader(data, offset)
+	if err != nil {
+		return offset, err
+	}
+	if err = d.checkLimits(h, offset, depth); err != nil {
+		return offset, err
+	}
+
+	if h.Kind == Nil {
+		v.Set(reflect.Zero(v.Type()))
+		return offset + h.Size, nil
+	}
+	if h.Kind != Map {
+		return offset, typeError(h, v.Type(), offset)
+	}
+
+	next := offset + h.Size
+
+	// Every element is at least one byte long, don't trust a bigger length
+	if h.Elements() > len(data)-next {
+		return offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(h.Code) + " elements"}
+	}
+
+	pairs := reflect.MakeSlice(v.Type(), h.Length, h.Length)
+	keyOffsets := make([]int, h.Length)
+	for i := 0; i < h.Length; i++ {
+		pair := pairs.Index(i)
+		keyOffsets[i] = next
+		if next, err = d.mapKey(data, next, pair.Field(0), depth+1); err != nil {
+			return next, err
+		}
+		if next, err = d.decode(data, next, pair.Field(1), depth+1); err != nil {
+			return next, err
+		}
+	}
+
+	if d.DuplicateKeys != DuplicateAllow {
+		if pairs, err = d.dedupPairs(pairs, keyOffsets); err != nil {
+			return offset, err
+		}
+	}
+	v.Set(pairs)
+	return next, nil
+}
+
+// dedupPairs returns the pairs without the duplicated keys, according to the duplicate key policy,
+// in the same order. The keys are compared by their encoding with the default formats, so that
+// the same number written with different formats is the same key. keyOffsets contains the offsets
+// of the keys in the MessagePack data.
+func (d *DecoderState) dedupPairs(pairs reflect.Value, keyOffsets []int) (reflect.Value, error) {
+	state := encode.NewEncoderState()
+
+	// Index of the pair kept for every encoded key
+	keys := make([]string, pairs.Len())
+	kept := make(map[string]int, pairs.Len())
+	for i := range keys {
+		key := pairs.Index(i).Field(0)
+
+		var b bytes.Buffer
+		if _, err := state.TypeWrapper(key).WriteTo(&b); err != nil {
+			return pairs, err
+		}
+		keys[i] = b.String()
+
+		_, already := kept[keys[i]]
+		switch {
+		case already && d.DuplicateKeys == DuplicateError:
+			return pairs, utils.DuplicatedKeyError{Key: key.Interface(), Offset: keyOffsets[i]}
+		case !already || d.DuplicateKeys == DuplicateLast:
+			kept[keys[i]] = i
+		}
+	}
+
+	result := reflect.MakeSlice(pairs.Type(), 0, len(kept))
+	for i := range keys {
+		if kept[keys[i]] == i {
+			result = reflect.Append(result, pairs.Index(i))
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+package decode
+
+import (
+	"encoding/binary"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"math"
+)
+
+// MaxDepth is the maximum nesting depth of arrays and maps accepted when decoding.
+const MaxDepth = 10000
+
+// ReadUint returns the value of the uint object with header h that starts at data[offset].
+// The payload must have been checked to be in bounds.
+func ReadUint(data []byte, offset int, h Header) uint64 {
+	payload := data[offset+h.Size:]
+
+	switch h.Code {
+	case types.Uint8:
+		return uint64(payload[0])
+	case types.Uint16:
+		return uint64(binary.BigEndian.Uint16(payload))
+	case types.Uint32:
+		return uint64(binary.BigEndian.Uint32(payload))
+	case types.Uint64:
+		return binary.BigEndian.Uint64(payload)
+	default:
+		// positive fix int
+		return uint64(h.Code)
+	}
+}
+
+// ReadInt returns the value of the int object with header h that starts at data[offset].
+// The payload must have been checked to be in bounds.
+func ReadInt(data []byte, offset int, h Header) int64 {
+	payload := data[offset+h.Size:]
+
+	switch h.Code {
+	case types.Int8:
+		return int64(int8(payload[0]))
+	case types.Int16:
+		return int64(int16(binary.BigEndian.Uint16(payload)))
+	case types.Int32:
+		return int64(int32(binary.BigEndian.Uint32(payload)))
+	case types.Int64:
+		return int64(binary.BigEndian.Uint64(payload))
+	default:
+		// negative fix int
+		return int64(int8(h.Code))
+	}
+}
+
+// ReadFloat returns the value of the float object with header h that starts at data[offset].
+// The payload must have been checked to be in bounds.
+func ReadFloat(data []byte, offset int, h Header) float64 {
+	payload := data[offset+h.Size:]
+
+	if h.Code == types.Float32 {
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(payload)))
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(payload))
+}
+
+// Parse decodes the object that starts at data[offset] into its MessagePack type representation.
+// It returns the decoded object and the offset of the first byte after it.
+// Binary and external payloads are copied, so the result doesn't reference data.
+func Parse(data []byte, offset int) (utils.MessagePackType, int, error) {
+	return (&DecoderState{}).Parse(data, offset, 0)
+}
+
+// Parse is like the Parse function, but it checks the nesting depth and the length
+// of the objects against the limits of d, starting from the given depth.
+func (d *DecoderState) Parse(data []byte, offset int, depth int) (utils.MessagePackType, int, error) {
+	h, err := ReadHeader(data, offset)
+	if err != nil {
+		return nil, offset, err
+	}
+	if err = d.checkLimits(h, offset, depth); err != nil {
+		return nil, offset, err
+	}
+
+	start := offset + h.Size
+	end := start + h.Payload()
+	if end > len(data) {
+		return nil, offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(h.Code) + " payload"}
+	}
+
+	switch h.Kind {
+	case Nil:
+		return types.Nil{}, end, nil
+	case Boolean:
+		return types.Boolean(h.Code == types.True), end, nil
+	case Int:
+		return types.Int(ReadInt(data, offset, h)), end, nil
+	case Uint:
+		return types.Uint(ReadUint(data, offset, h)), end, nil
+	case Float:
+		return types.Float(ReadFloat(data, offset, h)), end, nil
+	case String:
+		return types.String(data[start:end]), end, nil
+	case Binary:
+		return types.Binary(append([]byte(nil), data[start:end]...)), end, nil
+	case Ext:
+		return types.External{Type: byte(h.ExtType), Data: append([]byte(nil), data[start:end]...)}, end, nil
+	}
+
+	// Every element is at least one byte long, don't trust a bigger length
+	if h.Elements() > len(data)-end {
+		return nil, offset, utils.SyntaxError{Offset: offset, Desc: "truncated " + FormatName(h.Code) + " elements"}
+	}
+
+	if h.Kind == Array {
+		array := make(types.Array, h.Length)
+		for i := range array {
+			if array[i], end, err = d.Parse(data, end, depth+1); err != nil {
+				return nil, end, err
+			}
+		}
+		return array, end, nil
+	}
+
+	m := make(types.Map, h.Length)
+	for i := range m {
+		if m[i].Key, end, err = d.Parse(data, end, depth+1); err != nil {
+			return nil, end, err
+		}
+		if m[i].Value, end, err = d.Parse(data, end, depth+1); err != nil {
+			return nil, end, err
+		}
+	}
+	return m, end, nil
+}
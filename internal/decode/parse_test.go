@@ -0,0 +1,66 @@
+package decode
+
+import (
+	"bytes"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	inputs := []utils.MessagePackType{
+		types.Nil{},
+		types.Boolean(false),
+		types.Int(-40000),
+		types.Int(-1 << 46),
+		types.Uint(1 << 46),
+		types.Uint(200),
+		types.Float(9.5),
+		types.Float(1.37),
+		types.String("hello world"),
+		types.Binary{0x01, 0x02, 0x03},
+		types.External{Type: 0xFF, Data: []byte{0x62, 0x24, 0xD1, 0x20}},
+		types.Array{types.Array{types.Nil{}}, types.Uint(1), types.String("foo")},
+		types.Map{
+			{Key: types.Int(-8), Value: types.Uint(32000)},
+			{Key: types.Array{types.Uint(1)}, Value: types.Map{}},
+			{Key: types.Int(-8), Value: types.Boolean(true)},
+		},
+	}
+
+	data := make([]utils.WriteTestData, len(inputs))
+	for i, input := range inputs {
+		var buffer bytes.Buffer
+		if _, err := input.WriteTo(&buffer); err != nil {
+			t.Fatal(err.Error())
+		}
+
+		result, end, err := Parse(buffer.Bytes(), 0)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if end != buffer.Len() {
+			t.Errorf("Invalid end offset. Function returned %d. Expected %d.", end, buffer.Len())
+		}
+
+		data[i] = utils.WriteTestData{Input: result, Expected: buffer.Bytes()}
+	}
+
+	utils.TypeWriteToTest(t, data)
+}
+
+func TestParse_Error(t *testing.T) {
+	data := [][]byte{
+		{0xC1},
+		{0x92, 0xC0},
+		{0xCD, 0x01},
+		{0xDD, 0x7F, 0xFF, 0xFF, 0xFF},
+		append(bytes.Repeat([]byte{0x91}, MaxDepth+1), 0xC0),
+	}
+
+	for _, test := range data {
+		if _, _, err := Parse(test, 0); err == nil {
+			t.Errorf("Error was expected for %v.", test)
+		}
+	}
+}
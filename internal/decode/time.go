@@ -0,0 +1,84 @@
+package decode
+
+import (
+	"encoding/binary"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"sync"
+	"time"
+)
+
+// ReadTimestamp decodes the payload of a MessagePack timestamp (external type -1).
+// The result is in UTC.
+func ReadTimestamp(payload []byte) (time.Time, error) {
+	var seconds int64
+	var nanoSeconds int64
+
+	switch len(payload) {
+	case 4:
+		// timestamp 32
+		seconds = int64(binary.BigEndian.Uint32(payload))
+	case 8:
+		// timestamp 64
+		data := binary.BigEndian.Uint64(payload)
+		nanoSeconds = int64(data >> 34)
+		seconds = int64(data & 0x00000003FFFFFFFF)
+	case 12:
+		// timestamp 96
+		nanoSeconds = int64(binary.BigEndian.Uint32(payload))
+		seconds = int64(binary.BigEndian.Uint64(payload[4:]))
+	default:
+		return time.Time{}, utils.InvalidTypeError{Type: "timestamp with invalid length"}
+	}
+
+	if nanoSeconds > 999999999 {
+		return time.Time{}, utils.InvalidTypeError{Type: "timestamp with invalid nanoseconds"}
+	}
+
+	return time.Unix(seconds, nanoSeconds).UTC(), nil
+}
+
+// locations contains the locations already loaded by ReadZonedTime.
+// The names that can't be loaded aren't stored, so they can't fill it.
+var locations sync.Map
+
+// ReadZonedTime decodes the payload of the external type written by encode.ZonedTimeBytes.
+// The location is loaded with time.LoadLocation, and if it isn't available, or its offset
+// is different from the encoded one, a fixed zone with the same name and offset is used.
+func ReadZonedTime(payload []byte) (time.Time, error) {
+	if len(payload) < 16 {
+		return time.Time{}, utils.InvalidTypeError{Type: "zoned time with invalid length"}
+	}
+
+	seconds := int64(binary.BigEndian.Uint64(payload))
+	nanoSeconds := int64(binary.BigEndian.Uint32(payload[8:]))
+	offset := int(int32(binary.BigEndian.Uint32(payload[12:])))
+	name := string(payload[16:])
+
+	if nanoSeconds > 999999999 {
+		return time.Time{}, utils.InvalidTypeError{Type: "zoned time with invalid nanoseconds"}
+	}
+
+	t := time.Unix(seconds, nanoSeconds)
+	if name != "" {
+		if loc, ok := loadLocation(name); ok {
+			if _, locOffset := t.In(loc).Zone(); locOffset == offset {
+				return t.In(loc), nil
+			}
+		}
+	}
+	return t.In(time.FixedZone(name, offset)), nil
+}
+
+// loadLocation returns the location with the given IANA name, if available.
+func loadLocation(name string) (*time.Location, bool) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), true
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil || loc == time.Local {
+		return nil, false
+	}
+	locations.Store(name, loc)
+	return loc, true
+}
@@ -0,0 +1,102 @@
+package decode
+
+import (
+	"github.com/ErikPelli/sbor/internal/encode"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestReadTimestamp(t *testing.T) {
+	data := []struct {
+		input    []byte
+		expected time.Time
+		name     string
+	}{
+		{input: []byte{0x62, 0x24, 0xD1, 0x20}, expected: time.Unix(1646580000, 0), name: "Timestamp 32"},
+		{input: []byte{0x00, 0x00, 0xC0, 0xE4, 0x62, 0x24, 0xD1, 0x20}, expected: time.Unix(1646580000, 12345), name: "Timestamp 64"},
+		{input: []byte{0x00, 0x01, 0x81, 0xCD, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x9C}, expected: time.Unix(-100, 98765), name: "Timestamp 96"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ReadTimestamp(test.input)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			if !result.Equal(test.expected) || result.Location() != time.UTC {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestReadTimestamp_Error(t *testing.T) {
+	data := [][]byte{
+		{0x00, 0x01},
+		{0xFF, 0xFF, 0xFF, 0xFF, 0x62, 0x24, 0xD1, 0x20},
+		{0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	}
+
+	for _, test := range data {
+		if _, err := ReadTimestamp(test); err == nil {
+			t.Errorf("Error was expected for %v.", test)
+		}
+	}
+}
+
+func TestReadZonedTime(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	data := []struct {
+		input    time.Time
+		location string
+		name     string
+	}{
+		{input: time.Date(2022, 7, 1, 12, 30, 0, 500, rome), location: "Europe/Rome", name: "IANA location"},
+		{input: time.Date(2022, 1, 1, 12, 30, 0, 0, time.UTC), location: "UTC", name: "UTC"},
+		{input: time.Date(1960, 1, 1, 0, 0, 0, 0, time.FixedZone("Custom", -3*3600-1800)), location: "Custom", name: "Fixed zone"},
+		{input: time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local), location: "", name: "Local"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ReadZonedTime(encode.ZonedTimeBytes(test.input))
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			_, offset := result.Zone()
+			_, expectedOffset := test.input.Zone()
+			if !result.Equal(test.input) || offset != expectedOffset || result.Location().String() != test.location {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result, test.input)
+			}
+		})
+	}
+
+	// The offset of a different version of the location is preserved
+	payload := encode.ZonedTimeBytes(time.Date(2022, 7, 1, 12, 30, 0, 0, rome))
+	payload[15]++
+	if result, err := ReadZonedTime(payload); err != nil || result.Location().String() != "Europe/Rome" {
+		t.Errorf("Invalid result. Function returned %v, %v.", result, err)
+	} else if _, offset := result.Zone(); offset != 7201 {
+		t.Errorf("Invalid offset. Function returned %d. Expected %d.", offset, 7201)
+	}
+}
+
+func TestReadZonedTime_Error(t *testing.T) {
+	data := [][]byte{
+		{0x00, 0x01},
+		{0, 0, 0, 0, 0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF, 0, 0, 0, 0},
+	}
+
+	for _, test := range data {
+		if _, err := ReadZonedTime(test); err == nil {
+			t.Errorf("Error was expected for %v.", test)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package encode
+
+import (
+	"bytes"
+	"github.com/ErikPelli/sbor/internal/types"
+	"reflect"
+	"sort"
+)
+
+// compositeKey reports whether the map key type t is an array, a struct or a pointer.
+// The maps with these keys are always sorted, like in canonical mode, so that their
+// encoding doesn't depend on the iteration order of the Go map.
+func compositeKey(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array, reflect.Struct, reflect.Ptr:
+		return true
+	}
+	return false
+}
+
+// sortMap sorts the entries of m by the bytewise lexicographic order of the encoding
+// of their keys, so that equal maps always have the same encoding.
+// The keys are replaced by their encoding, because some types, like the structs,
+// can be written only once. A key that can't be encoded is sorted by the part
+// written before the error, that is then returned when writing the map.
+func sortMap(m types.Map) {
+	keys := make([][]byte, len(m))
+	for i := range m {
+		var b bytes.Buffer
+		if _, err := m[i].Key.WriteTo(&b); err == nil {
+			m[i].Key = types.Encoded(b.Bytes())
+		}
+		keys[i] = b.Bytes()
+	}
+	sort.Sort(mapSorter{m, keys})
+}
+
+// mapSorter sorts the entries of a map together with their encoded keys.
+type mapSorter struct {
+	m    types.Map
+	keys [][]byte
+}
+
+func (s mapSorter) Len() int {
+	return len(s.m)
+}
+
+func (s mapSorter) Less(i, j int) bool {
+	return bytes.Compare(s.keys[i], s.keys[j]) < 0
+}
+
+func (s mapSorter) Swap(i, j int) {
+	s.m[i], s.m[j] = s.m[j], s.m[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
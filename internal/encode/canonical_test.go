@@ -0,0 +1,52 @@
+package encode
+
+import (
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
+	"testing"
+)
+
+func Test_TypeWrapper_Canonical(t *testing.T) {
+	type point struct {
+		X int
+	}
+
+	canonical := NewEncoderState()
+	canonical.Canonical = true
+
+	data := []utils.WriteTestData{
+		{Input: canonical.TypeWrapper(reflect.ValueOf(map[string]int{"b": 2, "a": 1, "aa": 3})),
+			Expected: []byte{0x83, 0xA1, 0x61, 0x01, 0xA1, 0x62, 0x02, 0xA2, 0x61, 0x61, 0x03}, Name: "string keys"},
+		{Input: canonical.TypeWrapper(reflect.ValueOf(map[int]bool{-1: true, 200: false, 1: true})),
+			Expected: []byte{0x83, 0x01, 0xC3, 0xD1, 0x00, 0xC8, 0xC2, 0xFF, 0xC3}, Name: "integer keys"},
+		{Input: canonical.TypeWrapper(reflect.ValueOf(map[interface{}]int{"a": 1, 2: 2})),
+			Expected: []byte{0x82, 0x02, 0x02, 0xA1, 0x61, 0x01}, Name: "mixed keys"},
+		{Input: canonical.TypeWrapper(reflect.ValueOf(map[point]int{{2}: 2, {1}: 1})),
+			Expected: []byte{0x82, 0x81, 0xA1, 0x58, 0x01, 0x01, 0x81, 0xA1, 0x58, 0x02, 0x02}, Name: "struct keys"},
+	}
+
+	utils.TypeWriteToTest(t, data)
+}
+
+func Test_TypeWrapper_CompositeKeys(t *testing.T) {
+	type point struct {
+		X int
+	}
+
+	// Sorted also without the canonical mode
+	state := NewEncoderState()
+	one, two := &point{1}, &point{2}
+
+	data := []utils.WriteTestData{
+		{Input: state.TypeWrapper(reflect.ValueOf(map[[2]int]bool{{1, 2}: true, {0, 5}: false})),
+			Expected: []byte{0x82, 0x92, 0x00, 0x05, 0xC2, 0x92, 0x01, 0x02, 0xC3}, Name: "array keys"},
+		{Input: state.TypeWrapper(reflect.ValueOf(map[point]int{{2}: 2, {1}: 1})),
+			Expected: []byte{0x82, 0x81, 0xA1, 0x58, 0x01, 0x01, 0x81, 0xA1, 0x58, 0x02, 0x02}, Name: "struct keys"},
+		{Input: state.TypeWrapper(reflect.ValueOf(map[*point]int{two: 2, one: 1})),
+			Expected: []byte{0x82, 0x81, 0xA1, 0x58, 0x01, 0x01, 0x81, 0xA1, 0x58, 0x02, 0x02}, Name: "pointer keys"},
+	}
+
+	for i := 0; i < 5; i++ {
+		utils.TypeWriteToTest(t, data)
+	}
+}
@@ -0,0 +1,101 @@
+package encode
+
+import (
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
+	"reflect"
+)
+
+// extInterface is an interface type associated with a custom encoding.
+type extInterface struct {
+	typeInvolved reflect.Type
+	handler      ExtUserHandler
+}
+
+// SetExternalInterfaceHandler associate an interface type with a custom encoding
+// function provided by the user, used for all the types that implement it.
+// The interface type is passed as a nil pointer to the interface, like (*error)(nil).
+// Registering again the same interface replaces its handler, keeping its precedence.
+func (e *EncoderState) SetExternalInterfaceHandler(pointerToInterface interface{}, handler ExtUserHandler) error {
+	t := reflect.TypeOf(pointerToInterface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		return utils.InvalidTypeError{Type: "external interface not passed as a pointer to an interface"}
+	}
+	if err := checkHandler(handler); err != nil {
+		return err
+	}
+
+	for i := range e.extInterfaces {
+		if e.extInterfaces[i].typeInvolved == t.Elem() {
+			e.extInterfaces[i].handler = handler
+			return nil
+		}
+	}
+	e.extInterfaces = append(e.extInterfaces, extInterface{typeInvolved: t.Elem(), handler: handler})
+	return nil
+}
+
+// checkHandler checks the external type code and the function of handler.
+func checkHandler(handler ExtUserHandler) error {
+	// Max value is 127
+	if handler.Type > 0x7F {
+		return utils.OutOfBoundError{Key: int(handler.Type)}
+	}
+
+	if handler.Encoder == nil && (handler.Size == nil || handler.Writer == nil) {
+		return utils.InvalidTypeError{Type: "nil as function"}
+	}
+	return nil
+}
+
+// streamExternal returns the external type of value, whose payload is written by the Writer of handler.
+func streamExternal(handler ExtUserHandler, value interface{}) utils.MessagePackTypeEncoder {
+	size, err := handler.Size(value)
+	if err != nil {
+		return utils.ErrorMessagePackType(err.Error())
+	}
+	return types.StreamExternal{
+		Type: handler.Type,
+		Size: size,
+		Write: func(w io.Writer) error {
+			return handler.Writer(value, w)
+		},
+	}
+}
+
+// extHandler returns the handler of the user external type that matches the valid value,
+// along with the value to pass to it. The registrations are matched in this order:
+//
+//   1. the type of value
+//   2. the type of the value pointed to, for a non-nil pointer, or the pointer to
+//      the type of value, that receives a pointer to a copy of value
+//   3. the interfaces implemented by the type of value, in order of registration
+//
+// A nil pointer never matches, and it is encoded as nil.
+func (e *EncoderState) extHandler(value reflect.Value) (ExtUserHandler, reflect.Value, bool) {
+	t := value.Type()
+	if t.Kind() == reflect.Ptr && value.IsNil() {
+		return ExtUserHandler{}, value, false
+	}
+	if handler, ok := e.extUserHandlers[t]; ok {
+		return handler, value, true
+	}
+
+	if t.Kind() == reflect.Ptr {
+		if handler, ok := e.extUserHandlers[t.Elem()]; ok {
+			return handler, value.Elem(), true
+		}
+	} else if handler, ok := e.extUserHandlers[reflect.PtrTo(t)]; ok {
+		pointer := reflect.New(t)
+		pointer.Elem().Set(value)
+		return handler, pointer, true
+	}
+
+	for _, i := range e.extInterfaces {
+		if t.Implements(i.typeInvolved) {
+			return i.handler, value, true
+		}
+	}
+	return ExtUserHandler{}, value, false
+}
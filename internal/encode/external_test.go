@@ -0,0 +1,138 @@
+package encode
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type testExtValue struct {
+	value byte
+}
+
+func (t testExtValue) String() string {
+	return "value"
+}
+
+type testExtPointer struct {
+	value byte
+}
+
+func (t *testExtPointer) String() string {
+	return "pointer"
+}
+
+type testExtBoth struct{}
+
+func (t testExtBoth) String() string {
+	return "both"
+}
+
+func (t testExtBoth) Error() string {
+	return "error"
+}
+
+// testExtHandler returns a handler that writes the fixed payload, after the
+// first byte of the value received, used to check which value was passed.
+func testExtHandler(code byte, payload byte) ExtUserHandler {
+	return ExtUserHandler{Type: code, Encoder: func(i interface{}) ([]byte, error) {
+		switch v := i.(type) {
+		case testExtValue:
+			return []byte{v.value, payload}, nil
+		case *testExtPointer:
+			return []byte{v.value, payload}, nil
+		case fmt.Stringer:
+			return []byte{v.String()[0], payload}, nil
+		}
+		return nil, fmt.Errorf("unexpected %T", i)
+	}}
+}
+
+func Test_TypeWrapper_ExternalMatching(t *testing.T) {
+	state := NewEncoderState()
+	if err := state.SetExternalTypeHandler(testExtValue{}, testExtHandler(1, 0xAA)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := state.SetExternalTypeHandler(&testExtPointer{}, testExtHandler(2, 0xBB)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := state.SetExternalInterfaceHandler((*fmt.Stringer)(nil), testExtHandler(3, 0xCC)); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	data := []utils.WriteTestData{
+		{Input: state.TypeWrapper(reflect.ValueOf(testExtValue{5})), Expected: []byte{0xD5, 0x01, 0x05, 0xAA}, Name: "value"},
+		{Input: state.TypeWrapper(reflect.ValueOf(&testExtValue{5})), Expected: []byte{0xD5, 0x01, 0x05, 0xAA}, Name: "pointer to value"},
+		{Input: state.TypeWrapper(reflect.ValueOf(&testExtPointer{6})), Expected: []byte{0xD5, 0x02, 0x06, 0xBB}, Name: "pointer"},
+		{Input: state.TypeWrapper(reflect.ValueOf(testExtPointer{6})), Expected: []byte{0xD5, 0x02, 0x06, 0xBB}, Name: "value of pointer"},
+		{Input: state.TypeWrapper(reflect.ValueOf((*testExtValue)(nil))), Expected: []byte{0xC0}, Name: "nil pointer to value"},
+		{Input: state.TypeWrapper(reflect.ValueOf((*testExtPointer)(nil))), Expected: []byte{0xC0}, Name: "nil pointer"},
+		{Input: state.TypeWrapper(reflect.ValueOf(reflect.Int)), Expected: []byte{0xD5, 0x03, 'i', 0xCC}, Name: "interface"},
+	}
+
+	utils.TypeWriteToTest(t, data)
+}
+
+func TestEncoderState_SetExternalInterfaceHandler(t *testing.T) {
+	state := NewEncoderState()
+	if err := state.SetExternalInterfaceHandler((*fmt.Stringer)(nil), testExtHandler(1, 0xAA)); err != nil {
+		t.Fatal(err.Error())
+	}
+	if err := state.SetExternalInterfaceHandler((*error)(nil), testExtHandler(2, 0xBB)); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	// The first registered interface has precedence, also when replaced
+	if err := state.SetExternalInterfaceHandler((*fmt.Stringer)(nil), testExtHandler(3, 0xCC)); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	data := []utils.WriteTestData{
+		{Input: state.TypeWrapper(reflect.ValueOf(testExtBoth{})), Expected: []byte{0xD5, 0x03, 'b', 0xCC}, Name: "first interface"},
+	}
+	utils.TypeWriteToTest(t, data)
+
+	invalid := []interface{}{nil, 0, fmt.Stringer(nil), new(int)}
+	for _, i := range invalid {
+		if err := state.SetExternalInterfaceHandler(i, testExtHandler(1, 0)); err == nil {
+			t.Errorf("Error was expected with %T.", i)
+		}
+	}
+	if err := state.SetExternalInterfaceHandler((*error)(nil), ExtUserHandler{Type: 0x80, Encoder: testExtHandler(1, 0).Encoder}); err == nil {
+		t.Error("Error was expected with an invalid code.")
+	}
+}
+
+func Test_TypeWrapper_ExternalStream(t *testing.T) {
+	state := NewEncoderState()
+	err := state.SetExternalTypeHandler("", ExtUserHandler{
+		Type: 0x10,
+		Size: func(i interface{}) (int, error) {
+			if i.(string) == "" {
+				return 0, errors.New("empty")
+			}
+			return len(i.(string)), nil
+		},
+		Writer: func(i interface{}, w io.Writer) error {
+			_, err := io.WriteString(w, i.(string))
+			return err
+		},
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	utils.TypeWriteToTest(t, []utils.WriteTestData{
+		{Input: state.TypeWrapper(reflect.ValueOf("abc")), Expected: []byte{0xC7, 0x03, 0x10, 'a', 'b', 'c'}, Name: "stream"},
+	})
+	utils.TypeWriteToTest(t, []utils.WriteTestData{
+		{Input: state.TypeWrapper(reflect.ValueOf("")), Expected: []byte{}, Name: "size error"},
+	}, true)
+
+	if err = state.SetExternalTypeHandler("", ExtUserHandler{Type: 0x10, Size: func(interface{}) (int, error) { return 0, nil }}); err == nil {
+		t.Error("Error was expected without Writer.")
+	}
+}
@@ -0,0 +1,114 @@
+package encode
+
+import (
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"math"
+	"reflect"
+)
+
+// FloatPolicy chooses the format of the encoded floating point numbers.
+type FloatPolicy uint8
+
+const (
+	FloatShortest FloatPolicy = iota // float32 when the value is represented exactly, else float64
+	FloatPreserve                    // Width of the Go type
+	FloatAlways64                    // Always float64
+)
+
+// IntPolicy chooses the format of the encoded integers.
+type IntPolicy uint8
+
+const (
+	IntShortest IntPolicy = iota // Smallest format that contains the value
+	IntPreserve                  // Width of the Go type
+)
+
+// NumberOptions contains the options used to encode the numbers.
+type NumberOptions struct {
+	Float        FloatPolicy
+	Int          IntPolicy
+	PreserveSign bool // Write the signed Go integers always with the int formats
+}
+
+// withOptions returns the numbers options modified by the options of a struct field's tag:
+// "float=shortest", "float=preserve", "float=64", "int=shortest", "int=preserve" and "signed".
+func (n NumberOptions) withOptions(options utils.Options) (NumberOptions, error) {
+	if value, ok := options.Value("float"); ok {
+		switch value {
+		case "shortest":
+			n.Float = FloatShortest
+		case "preserve":
+			n.Float = FloatPreserve
+		case "64":
+			n.Float = FloatAlways64
+		default:
+			return n, utils.InvalidArgumentError{Desc: "unknown float option " + value}
+		}
+	}
+
+	if value, ok := options.Value("int"); ok {
+		switch value {
+		case "shortest":
+			n.Int = IntShortest
+		case "preserve":
+			n.Int = IntPreserve
+		default:
+			return n, utils.InvalidArgumentError{Desc: "unknown int option " + value}
+		}
+	}
+
+	if options.Contains("signed") {
+		n.PreserveSign = true
+	}
+	return n, nil
+}
+
+// intType returns the MessagePack type of the signed integer value.
+func (n NumberOptions) intType(value reflect.Value) utils.MessagePackTypeEncoder {
+	i := value.Int()
+
+	switch {
+	case n.Int == IntPreserve:
+		return types.FixedInt{Value: i, Size: value.Type().Bits() / 8}
+	case n.PreserveSign && i >= 0:
+		// Never a positive fixint, that is read as unsigned
+		return types.FixedInt{Value: i, Size: intSize(i)}
+	default:
+		return types.Int(i)
+	}
+}
+
+// uintType returns the MessagePack type of the unsigned integer value.
+func (n NumberOptions) uintType(value reflect.Value) utils.MessagePackTypeEncoder {
+	if n.Int == IntPreserve {
+		return types.FixedUint{Value: value.Uint(), Size: value.Type().Bits() / 8}
+	}
+	return types.Uint(value.Uint())
+}
+
+// floatType returns the MessagePack type of the floating point value.
+func (n NumberOptions) floatType(value reflect.Value) utils.MessagePackTypeEncoder {
+	switch n.Float {
+	case FloatPreserve:
+		return types.FixedFloat{Value: value.Float(), Size: value.Type().Bits() / 8}
+	case FloatAlways64:
+		return types.FixedFloat{Value: value.Float(), Size: 8}
+	default:
+		return types.Float(value.Float())
+	}
+}
+
+// intSize returns the size in bytes of the smallest int format that contains i.
+func intSize(i int64) int {
+	switch {
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		return 1
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		return 2
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		return 4
+	default:
+		return 8
+	}
+}
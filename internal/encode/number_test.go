@@ -0,0 +1,68 @@
+package encode
+
+import (
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
+	"testing"
+)
+
+func Test_TypeWrapper_NumberOptions(t *testing.T) {
+	preserve := NewEncoderState()
+	preserve.Numbers = NumberOptions{Float: FloatPreserve, Int: IntPreserve}
+
+	always64 := NewEncoderState()
+	always64.Numbers = NumberOptions{Float: FloatAlways64, PreserveSign: true}
+
+	data := []utils.WriteTestData{
+		{Input: preserve.TypeWrapper(reflect.ValueOf(9.5)), Expected: []byte{types.Float64, 0x40, 0x23, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, Name: "preserve float64"},
+		{Input: preserve.TypeWrapper(reflect.ValueOf(float32(9.5))), Expected: []byte{types.Float32, 0x41, 0x18, 0x00, 0x00}, Name: "preserve float32"},
+		{Input: preserve.TypeWrapper(reflect.ValueOf(int16(5))), Expected: []byte{types.Int16, 0x00, 0x05}, Name: "preserve int16"},
+		{Input: preserve.TypeWrapper(reflect.ValueOf(uint32(5))), Expected: []byte{types.Uint32, 0x00, 0x00, 0x00, 0x05}, Name: "preserve uint32"},
+		{Input: always64.TypeWrapper(reflect.ValueOf(float32(9.5))), Expected: []byte{types.Float64, 0x40, 0x23, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, Name: "always 64"},
+		{Input: always64.TypeWrapper(reflect.ValueOf(int64(5))), Expected: []byte{types.Int8, 0x05}, Name: "signed"},
+		{Input: always64.TypeWrapper(reflect.ValueOf(int64(-5))), Expected: []byte{0xFB}, Name: "signed negative"},
+		{Input: always64.TypeWrapper(reflect.ValueOf(int64(1 << 40))), Expected: []byte{types.Int64, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00}, Name: "signed int64"},
+		{Input: always64.TypeWrapper(reflect.ValueOf(uint64(5))), Expected: []byte{0x05}, Name: "unsigned"},
+	}
+
+	utils.TypeWriteToTest(t, data)
+}
+
+func TestEncodingStruct_WriteTo_NumberOptions(t *testing.T) {
+	exampleStruct := struct {
+		A float64   `sbor:"a,float=shortest"`
+		B []float32 `sbor:"b,float=64"`
+		C int32     `sbor:"c,int=preserve"`
+		D int       `sbor:"d,signed"`
+		E uint8     `sbor:"e"`
+	}{
+		A: 9.5,
+		B: []float32{9.5},
+		C: -1,
+		D: 1,
+		E: 1,
+	}
+
+	// The options of the fields override the ones of the encoder
+	state := NewEncoderState()
+	state.Numbers = NumberOptions{Float: FloatPreserve, Int: IntPreserve}
+
+	data := []utils.WriteTestData{
+		{Input: NewEncodingStruct(types.Struct(reflect.ValueOf(exampleStruct)), state), Expected: []byte{0x85,
+			0xA1, 0x61, types.Float32, 0x41, 0x18, 0x00, 0x00,
+			0xA1, 0x62, 0x91, types.Float64, 0x40, 0x23, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xA1, 0x63, types.Int32, 0xFF, 0xFF, 0xFF, 0xFF,
+			0xA1, 0x64, types.Int64, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+			0xA1, 0x65, types.Uint8, 0x01}, Name: "number options"},
+	}
+
+	utils.TypeWriteToTest(t, data)
+
+	invalid := struct {
+		A float64 `sbor:"a,float=32"`
+	}{}
+	utils.TypeWriteToTest(t, []utils.WriteTestData{
+		{Input: NewEncodingStruct(types.Struct(reflect.ValueOf(invalid)), NewEncoderState()), Expected: []byte{}, Name: "invalid option"},
+	}, true)
+}
@@ -5,8 +5,16 @@ import (
 	"github.com/ErikPelli/sbor/internal/utils"
 	"io"
 	"reflect"
+	"strconv"
 )
 
+// zeroer is implemented by the types that define their own zero value.
+type zeroer interface {
+	IsZero() bool
+}
+
+var zeroerType = reflect.TypeOf((*zeroer)(nil)).Elem()
+
 // EncodingStruct is the internal representation of a Go struct
 // which is used to do the encoding to MessagePack.
 type EncodingStruct struct {
@@ -97,7 +105,7 @@ func (e EncodingStruct) structParse(valueStruct reflect.Value) (result types.Map
 		}
 
 		// Tag parsing
-		tagValue := field.Tag.Get("sbor")
+		tagValue, _ := utils.LookupTag(field.Tag, e.state.TagNames)
 		tagName, tagOptions := utils.ParseTag(tagValue)
 
 		var name utils.MessagePackTypeEncoder
@@ -113,11 +121,14 @@ func (e EncodingStruct) structParse(valueStruct reflect.Value) (result types.Map
 			name = types.String(tagName)
 		}
 
-		if tagOptions.Contains("omitempty") {
-			// Skip zero value with omitempty option
-			if fieldValue.IsZero() {
-				continue
-			}
+		if tagOptions.Contains("omitempty") && isEmpty(fieldValue) {
+			// Skip empty value with omitempty option
+			continue
+		}
+
+		if tagOptions.Contains("omitzero") && isZero(fieldValue) {
+			// Skip zero value with omitzero option
+			continue
 		}
 
 		if tagOptions.Contains("structarray") {
@@ -168,10 +179,98 @@ func (e EncodingStruct) structParse(valueStruct reflect.Value) (result types.Map
 			usedKeysMap[checkName] = struct{}{}
 		}
 
+		// The number and time options of the tag apply to all the values in the field
+		state, errOptions := e.state.withOptions(tagOptions)
+		if errOptions != nil {
+			err = errOptions
+			return
+		}
+
+		value := state.TypeWrapper(fieldValue)
+		if tagOptions.Contains("string") && utils.Quotable(fieldValue.Type()) {
+			// Write booleans and numbers in their string form
+			value = state.compat(quote(fieldValue))
+		}
+
 		result = append(result, types.MessagePackMap{
-			Key:   name,
-			Value: e.state.TypeWrapper(fieldValue),
+			Key:   state.compat(name),
+			Value: value,
 		})
 	}
 	return
 }
+
+// withOptions returns the state modified by the number and time options of a struct
+// field's tag, or e itself if they don't change it.
+func (e *EncoderState) withOptions(options utils.Options) (*EncoderState, error) {
+	numbers, err := e.Numbers.withOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := e.Time.WithOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if numbers == e.Numbers && policy == e.Time {
+		return e, nil
+	}
+	state := *e
+	state.Numbers = numbers
+	state.Time = policy
+	return &state, nil
+}
+
+// isEmpty reports whether v is empty for the omitempty option, as defined by encoding/json:
+// false, 0, a nil pointer or interface, or an array, slice, map or string of length zero.
+// A struct is never empty.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Ptr, reflect.Interface:
+		return v.IsZero()
+	}
+	return false
+}
+
+// isZero reports whether v is zero for the omitzero option: the result of its IsZero
+// method if its type has one, else whether it's the zero value of its type.
+// A nil pointer or interface is always zero, without calling the method.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+	}
+
+	if v.Type().Implements(zeroerType) {
+		return v.Interface().(zeroer).IsZero()
+	}
+	return v.IsZero()
+}
+
+// quote returns the MessagePack string with the decimal form of the boolean or
+// number v, or of the value pointed to by v, for the string option.
+func quote(v reflect.Value) utils.MessagePackTypeEncoder {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return types.Nil{}
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return types.String(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return types.String(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return types.String(strconv.FormatUint(v.Uint(), 10))
+	default:
+		return types.String(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ErikPelli/sbor/internal/utils"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestEncodingStruct_WriteTo(t *testing.T) {
@@ -199,3 +200,84 @@ func TestEncodingStruct_WriteTo_SetCustomKeys_Invalid(t *testing.T) {
 
 	utils.TypeWriteToTest(t, data, true)
 }
+
+// period is empty when its end is before its start.
+type period struct {
+	Start, End int
+}
+
+func (p period) IsZero() bool {
+	return p.End < p.Start
+}
+
+func TestEncodingStruct_WriteTo_OmitEmpty(t *testing.T) {
+	type Test struct {
+		Slice []int           `sbor:"s,omitempty"`
+		Map   map[string]int  `sbor:"m,omitempty"`
+		Text  string          `sbor:"t,omitempty"`
+		None  [0]int          `sbor:"n,omitempty"`
+		Zero  struct{ A int } `sbor:"z,omitempty"`
+		Array [1]int          `sbor:"a,omitempty"`
+	}
+
+	// A zero struct or array is not empty
+	data := []utils.WriteTestData{
+		{Input: NewEncodingStruct(types.Struct(reflect.ValueOf(Test{Slice: []int{}, Map: map[string]int{}})), NewEncoderState()),
+			Expected: []byte{0x82, 0xA1, 0x7A, 0x81, 0xA1, 0x41, 0x00, 0xA1, 0x61, 0x91, 0x00}, Name: "empty"},
+		{Input: NewEncodingStruct(types.Struct(reflect.ValueOf(Test{Slice: []int{1}, Text: "x", Array: [1]int{2}})), NewEncoderState()),
+			Expected: []byte{0x84, 0xA1, 0x73, 0x91, 0x01, 0xA1, 0x74, 0xA1, 0x78, 0xA1, 0x7A, 0x81, 0xA1, 0x41, 0x00, 0xA1, 0x61, 0x91, 0x02}, Name: "not empty"},
+	}
+
+	utils.TypeWriteToTest(t, data)
+}
+
+func TestEncodingStruct_WriteTo_OmitZero(t *testing.T) {
+	type Test struct {
+		Period  period          `sbor:"p,omitzero"`
+		Pointer *period         `sbor:"r,omitzero"`
+		Slice   []int           `sbor:"s,omitzero"`
+		Time    time.Time       `sbor:"t,omitzero"`
+		Zero    struct{ A int } `sbor:"z,omitzero"`
+		Array   [1]int          `sbor:"a,omitzero"`
+	}
+
+	data := []utils.WriteTestData{
+		{Input: NewEncodingStruct(types.Struct(reflect.ValueOf(Test{Period: period{Start: 2, End: 1}})), NewEncoderState()),
+			Expected: []byte{0x80}, Name: "zero"},
+		{Input: NewEncodingStruct(types.Struct(reflect.ValueOf(Test{Pointer: &period{Start: 2, End: 1}, Slice: []int{}})), NewEncoderState()),
+			Expected: []byte{0x82, 0xA1, 0x70, 0x82, 0xA5, 0x53, 0x74, 0x61, 0x72, 0x74, 0x00, 0xA3, 0x45, 0x6E, 0x64, 0x00, 0xA1, 0x73, 0x90}, Name: "not zero"},
+	}
+
+	utils.TypeWriteToTest(t, data)
+}
+
+func TestEncodingStruct_WriteTo_String(t *testing.T) {
+	id := uint64(18446744073709551615)
+	exampleStruct := struct {
+		ID    *uint64 `sbor:"i,string"`
+		Nil   *int    `sbor:"n,string"`
+		Flag  bool    `sbor:"b,string"`
+		Float float32 `sbor:"f,string"`
+		Text  string  `sbor:"t,string"`
+		Slice []int8  `sbor:"s,string"`
+	}{
+		ID:    &id,
+		Flag:  true,
+		Float: 0.1,
+		Text:  "x",
+		Slice: []int8{-1},
+	}
+
+	enc := NewEncodingStruct(types.Struct(reflect.ValueOf(exampleStruct)), NewEncoderState())
+	data := []utils.WriteTestData{
+		{Input: enc, Expected: []byte{0x86,
+			0xA1, 0x69, 0xB4, 0x31, 0x38, 0x34, 0x34, 0x36, 0x37, 0x34, 0x34, 0x30, 0x37, 0x33, 0x37, 0x30, 0x39, 0x35, 0x35, 0x31, 0x36, 0x31, 0x35,
+			0xA1, 0x6E, 0xC0,
+			0xA1, 0x62, 0xA4, 0x74, 0x72, 0x75, 0x65,
+			0xA1, 0x66, 0xA3, 0x30, 0x2E, 0x31,
+			0xA1, 0x74, 0xA1, 0x78,
+			0xA1, 0x73, 0x91, 0xFF}, Name: "string option"},
+	}
+
+	utils.TypeWriteToTest(t, data)
+}
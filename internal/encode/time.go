@@ -2,11 +2,109 @@ package encode
 
 import (
 	"encoding/binary"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"math"
+	"reflect"
 	"time"
 )
 
 var Timestamp int8 = -1
 
+// DefaultZonedTimeType is the default external type code of the times with their zone.
+const DefaultZonedTimeType int8 = 89
+
+// TimePolicy chooses the format of the encoded time.Time values.
+type TimePolicy uint8
+
+const (
+	TimeExt       TimePolicy = iota // Timestamp external type
+	TimeUnix                        // Integer seconds since the Unix epoch
+	TimeUnixMilli                   // Integer milliseconds since the Unix epoch
+	TimeUnixNano                    // Integer nanoseconds since the Unix epoch
+	TimeUnixFloat                   // Floating point seconds since the Unix epoch
+	TimeRFC3339                     // RFC 3339 string, with the nanoseconds if not zero
+	TimeZoned                       // External type with the time zone, see ZonedTimeBytes
+)
+
+// Range of the times that can be written in nanoseconds as an int64.
+var (
+	minUnixNano = time.Unix(0, math.MinInt64)
+	maxUnixNano = time.Unix(0, math.MaxInt64)
+)
+
+// WithOptions returns the time policy modified by the options of a struct field's tag:
+// "time=ext", "time=unix", "time=unixmilli", "time=unixnano", "time=unixfloat", "time=rfc3339"
+// and "time=zoned".
+func (p TimePolicy) WithOptions(options utils.Options) (TimePolicy, error) {
+	value, ok := options.Value("time")
+	if !ok {
+		return p, nil
+	}
+
+	switch value {
+	case "ext":
+		return TimeExt, nil
+	case "unix":
+		return TimeUnix, nil
+	case "unixmilli":
+		return TimeUnixMilli, nil
+	case "unixnano":
+		return TimeUnixNano, nil
+	case "unixfloat":
+		return TimeUnixFloat, nil
+	case "rfc3339":
+		return TimeRFC3339, nil
+	case "zoned":
+		return TimeZoned, nil
+	default:
+		return p, utils.InvalidArgumentError{Desc: "unknown time option " + value}
+	}
+}
+
+// timeType returns the MessagePack type of the time instant t, using the time policy.
+// The numbers are written with the number options.
+func (e *EncoderState) timeType(t time.Time) utils.MessagePackTypeEncoder {
+	switch e.Time {
+	case TimeUnix:
+		return e.TypeWrapper(reflect.ValueOf(t.Unix()))
+	case TimeUnixMilli:
+		return e.TypeWrapper(reflect.ValueOf(t.UnixMilli()))
+	case TimeUnixNano:
+		if t.Before(minUnixNano) || t.After(maxUnixNano) {
+			return utils.ErrorMessagePackType("time.Time out of the range of the Unix nanoseconds")
+		}
+		return e.TypeWrapper(reflect.ValueOf(t.UnixNano()))
+	case TimeUnixFloat:
+		return e.TypeWrapper(reflect.ValueOf(float64(t.Unix()) + float64(t.Nanosecond())/1e9))
+	case TimeRFC3339:
+		text, err := t.MarshalText()
+		if err != nil {
+			return utils.ErrorMessagePackType(err.Error())
+		}
+		return e.compat(types.String(text))
+	case TimeZoned:
+		if e.CompatMode {
+			return utils.ErrorMessagePackType("ext types are unavailable in compatibility mode")
+		}
+		return types.External{Type: byte(e.ZonedTimeType), Data: ZonedTimeBytes(t)}
+	default:
+		if e.CompatMode {
+			return utils.ErrorMessagePackType("time.Time needs the timestamp ext type, unavailable in compatibility mode")
+		}
+		return NewTimestamp(t)
+	}
+}
+
+// NewTimestamp returns the MessagePack reserved external type
+// that represents the time instant t.
+func NewTimestamp(t time.Time) types.External {
+	return types.External{
+		Type: byte(Timestamp),
+		Data: convertTimestampToBytes(t),
+	}
+}
+
 func convertTimestampToBytes(t time.Time) []byte {
 	var result []byte
 	seconds := uint64(t.Unix())
@@ -35,3 +133,21 @@ func convertTimestampToBytes(t time.Time) []byte {
 
 	return result
 }
+
+// ZonedTimeBytes returns the payload of the external type that represents the time instant t
+// along with its time zone: the seconds since the Unix epoch (int64), the nanoseconds (uint32)
+// and the offset of the zone from UTC in seconds (int32), all big-endian, followed by the IANA
+// name of the location. The name is empty for time.Local, that is not portable.
+func ZonedTimeBytes(t time.Time) []byte {
+	name := t.Location().String()
+	if t.Location() == time.Local {
+		name = ""
+	}
+	_, offset := t.Zone()
+
+	result := make([]byte, 16, 16+len(name))
+	binary.BigEndian.PutUint64(result, uint64(t.Unix()))
+	binary.BigEndian.PutUint32(result[8:], uint32(t.Nanosecond()))
+	binary.BigEndian.PutUint32(result[12:], uint32(int32(offset)))
+	return append(result, name...)
+}
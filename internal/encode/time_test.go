@@ -2,6 +2,9 @@ package encode
 
 import (
 	"bytes"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -34,3 +37,69 @@ func TestExternal_WriteTo_FixExt(t *testing.T) {
 		})
 	}
 }
+
+func Test_TypeWrapper_TimePolicy(t *testing.T) {
+	instant := reflect.ValueOf(time.Unix(1646580000, 500000000))
+	newState := func(p TimePolicy) *EncoderState {
+		state := NewEncoderState()
+		state.Time = p
+		return state
+	}
+
+	data := []utils.WriteTestData{
+		{Input: newState(TimeExt).TypeWrapper(instant), Expected: []byte{types.FixExt8, 0xFF, 0x77, 0x35, 0x94, 0x00, 0x62, 0x24, 0xD1, 0x20}, Name: "ext"},
+		{Input: newState(TimeUnix).TypeWrapper(instant), Expected: []byte{types.Int32, 0x62, 0x24, 0xD1, 0x20}, Name: "unix"},
+		{Input: newState(TimeUnixMilli).TypeWrapper(instant), Expected: []byte{types.Int64, 0x00, 0x00, 0x01, 0x7F, 0x5F, 0xD0, 0xE6, 0xF4}, Name: "unix milliseconds"},
+		{Input: newState(TimeUnixNano).TypeWrapper(instant), Expected: []byte{types.Int64, 0x16, 0xD9, 0xD3, 0xC9, 0x58, 0x10, 0xA5, 0x00}, Name: "unix nanoseconds"},
+		{Input: newState(TimeUnixFloat).TypeWrapper(instant), Expected: []byte{types.Float64, 0x41, 0xD8, 0x89, 0x34, 0x48, 0x20, 0x00, 0x00}, Name: "unix float"},
+		{Input: newState(TimeRFC3339).TypeWrapper(reflect.ValueOf(instant.Interface().(time.Time).UTC())), Expected: append([]byte{0xB6}, "2022-03-06T15:20:00.5Z"...), Name: "rfc3339"},
+		{Input: newState(TimeZoned).TypeWrapper(reflect.ValueOf(time.Unix(1, 0).In(time.FixedZone("X", -60)))), Expected: []byte{types.Ext8, 0x11, 0x59,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xC4, 'X'}, Name: "zoned"},
+	}
+
+	utils.TypeWriteToTest(t, data)
+
+	compat := newState(TimeZoned)
+	compat.CompatMode = true
+
+	invalid := []utils.WriteTestData{
+		{Input: newState(TimeUnixNano).TypeWrapper(reflect.ValueOf(time.Date(2300, 1, 1, 0, 0, 0, 0, time.UTC))), Expected: []byte{}, Name: "unix nanoseconds out of range"},
+		{Input: newState(TimeRFC3339).TypeWrapper(reflect.ValueOf(time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC))), Expected: []byte{}, Name: "rfc3339 out of range"},
+		{Input: compat.TypeWrapper(instant), Expected: []byte{}, Name: "zoned in compatibility mode"},
+	}
+
+	utils.TypeWriteToTest(t, invalid, true)
+}
+
+func TestEncodingStruct_WriteTo_TimeOptions(t *testing.T) {
+	instant := time.Unix(100, 0)
+	exampleStruct := struct {
+		A time.Time   `sbor:"a,time=unix"`
+		B []time.Time `sbor:"b,time=unixmilli,int=preserve"`
+		C time.Time   `sbor:"c"`
+	}{
+		A: instant,
+		B: []time.Time{instant},
+		C: instant,
+	}
+
+	// The options of the fields override the ones of the encoder
+	state := NewEncoderState()
+	state.Time = TimeUnixNano
+
+	data := []utils.WriteTestData{
+		{Input: NewEncodingStruct(types.Struct(reflect.ValueOf(exampleStruct)), state), Expected: []byte{0x83,
+			0xA1, 0x61, 0x64,
+			0xA1, 0x62, 0x91, types.Int64, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x86, 0xA0,
+			0xA1, 0x63, types.Int64, 0x00, 0x00, 0x00, 0x17, 0x48, 0x76, 0xE8, 0x00}, Name: "time options"},
+	}
+
+	utils.TypeWriteToTest(t, data)
+
+	invalid := struct {
+		A time.Time `sbor:"a,time=iso"`
+	}{}
+	utils.TypeWriteToTest(t, []utils.WriteTestData{
+		{Input: NewEncodingStruct(types.Struct(reflect.ValueOf(invalid)), NewEncoderState()), Expected: []byte{}, Name: "invalid option"},
+	}, true)
+}
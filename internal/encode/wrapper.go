@@ -3,26 +3,65 @@ package encode
 import (
 	"github.com/ErikPelli/sbor/internal/types"
 	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
 	"reflect"
 	"time"
 )
 
 // ExtUserHandler is a function that handle a custom encode defined by the user,
 // and serializes to a MessagePack External.
+// Instead of Encoder, that returns the payload, it can have Size and Writer,
+// that return the size of the payload and write it to the output.
 type ExtUserHandler struct {
 	Type    byte
 	Encoder func(interface{}) ([]byte, error)
+	Size    func(interface{}) (int, error)
+	Writer  func(interface{}, io.Writer) error
 }
 
 // EncoderState contains data to correctly encode the current type.
 type EncoderState struct {
 	extUserHandlers map[reflect.Type]ExtUserHandler
+	extInterfaces   []extInterface // In order of precedence
+	TagNames        []string       // Keys of the struct field's tag, in order of precedence
+	Numbers         NumberOptions
+	Time            TimePolicy
+	ZonedTimeType   int8 // External type code of TimeZoned
+	CompatMode      bool // Old specification: no str8, bin and ext formats
+	Canonical       bool // Map keys sorted by their encoding
+}
+
+// DefaultTagNames contains the keys of the struct field's tag used by default.
+var DefaultTagNames = []string{"sbor"}
+
+// builtinEncoders contains the encoders of the public types that
+// wrap a MessagePack type, registered by the sbor package.
+var builtinEncoders = make(map[reflect.Type]func(e *EncoderState, v reflect.Value) utils.MessagePackTypeEncoder)
+
+// RegisterBuiltin associate a type with the function that returns its MessagePack type,
+// for every EncoderState. It must be called only during the package initialization.
+func RegisterBuiltin(typeInvolved interface{}, encoder func(e *EncoderState, v reflect.Value) utils.MessagePackTypeEncoder) {
+	builtinEncoders[reflect.TypeOf(typeInvolved)] = encoder
 }
 
 func NewEncoderState() *EncoderState {
 	return &EncoderState{
 		extUserHandlers: make(map[reflect.Type]ExtUserHandler),
+		TagNames:        DefaultTagNames,
+		ZonedTimeType:   DefaultZonedTimeType,
+	}
+}
+
+// Copy returns a copy of e with the same options and external types, that
+// can be modified without affecting e.
+func (e *EncoderState) Copy() *EncoderState {
+	c := *e
+	c.extUserHandlers = make(map[reflect.Type]ExtUserHandler, len(e.extUserHandlers))
+	for t, handler := range e.extUserHandlers {
+		c.extUserHandlers[t] = handler
 	}
+	c.extInterfaces = append([]extInterface(nil), e.extInterfaces...)
+	return &c
 }
 
 // SetExternalTypeHandler associate a specific data type with a custom encoding
@@ -33,13 +72,8 @@ func NewEncoderState() *EncoderState {
 // needs to do a type assertion and provide a byte array as result, along with an
 // eventual error (error = nil if there were no errors).
 func (e *EncoderState) SetExternalTypeHandler(typeInvolved interface{}, handler ExtUserHandler) error {
-	// Max value is 127
-	if handler.Type > 0x7F {
-		return utils.OutOfBoundError{Key: int(handler.Type)}
-	}
-
-	if handler.Encoder == nil {
-		return utils.InvalidTypeError{Type: "nil as function"}
+	if err := checkHandler(handler); err != nil {
+		return err
 	}
 
 	e.extUserHandlers[reflect.TypeOf(typeInvolved)] = handler
@@ -47,23 +81,39 @@ func (e *EncoderState) SetExternalTypeHandler(typeInvolved interface{}, handler
 	return nil
 }
 
+// compat converts a string to the old specification formats, in compatibility mode.
+func (e *EncoderState) compat(t utils.MessagePackTypeEncoder) utils.MessagePackTypeEncoder {
+	if s, ok := t.(types.String); ok && e.CompatMode {
+		return types.RawString(s)
+	}
+	return t
+}
+
 // TypeWrapper convert a primitive type into its messagepack
 // correspondent type using reflection.
 func (e *EncoderState) TypeWrapper(value reflect.Value) utils.MessagePackTypeEncoder {
 	if value.IsValid() {
-		// Reserved external
-		if value.Type() == reflect.TypeOf(time.Time{}) {
-			return types.External{
-				Type: byte(Timestamp),
-				Data: convertTimestampToBytes(value.Interface().(time.Time)),
-			}
+		// Reserved external, unless replaced by the user, or the format of the time policy
+		if _, replaced := e.extUserHandlers[value.Type()]; value.Type() == reflect.TypeOf(time.Time{}) && (e.Time != TimeExt || !replaced) {
+			return e.timeType(value.Interface().(time.Time))
+		}
+
+		// Public types with a MessagePack representation
+		if encoder, ok := builtinEncoders[value.Type()]; ok {
+			return encoder(e, value)
 		}
 
 		// User external
-		if len(e.extUserHandlers) > 0 {
-			handler, ok := e.extUserHandlers[value.Type()]
+		if len(e.extUserHandlers) > 0 || len(e.extInterfaces) > 0 {
+			handler, v, ok := e.extHandler(value)
+			if ok && e.CompatMode {
+				return utils.ErrorMessagePackType("ext types are unavailable in compatibility mode")
+			}
+			if ok && handler.Encoder == nil {
+				return streamExternal(handler, v.Interface())
+			}
 			if ok {
-				bytes, err := handler.Encoder(value.Interface())
+				bytes, err := handler.Encoder(v.Interface())
 				if err != nil {
 					return utils.ErrorMessagePackType(err.Error())
 				} else {
@@ -75,16 +125,16 @@ func (e *EncoderState) TypeWrapper(value reflect.Value) utils.MessagePackTypeEnc
 
 	switch value.Kind() {
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return types.Uint(value.Uint())
+		return e.Numbers.uintType(value)
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return types.Int(value.Int())
+		return e.Numbers.intType(value)
 
 	case reflect.Float32, reflect.Float64:
-		return types.Float(value.Float())
+		return e.Numbers.floatType(value)
 
 	case reflect.String:
-		return types.String(value.String())
+		return e.compat(types.String(value.String()))
 
 	case reflect.Bool:
 		return types.Boolean(value.Bool())
@@ -106,11 +156,17 @@ func (e *EncoderState) TypeWrapper(value reflect.Value) utils.MessagePackTypeEnc
 			mapR[i].Key = e.TypeWrapper(iter.Key())
 			mapR[i].Value = e.TypeWrapper(iter.Value())
 		}
+		if e.Canonical || compositeKey(value.Type().Key()) {
+			sortMap(mapR)
+		}
 		return mapR
 
 	case reflect.Slice:
 		if value.Type() == reflect.TypeOf([]byte(nil)) {
-			// Binary
+			// Binary, or raw string in compatibility mode
+			if e.CompatMode {
+				return types.RawString(value.Bytes())
+			}
 			return types.Binary(value.Bytes())
 		}
 		fallthrough // Use reflect.Array code
@@ -40,8 +40,29 @@ func (a Array) Len() int {
 // It implements io.WriterTo interface.
 // It returns the number of written bytes and an optional error.
 func (a Array) WriteTo(w io.Writer) (int64, error) {
-	var header []byte
 	length := len(a)
+	header, err := ArrayHeader(length)
+	if err != nil {
+		return 0, err
+	}
+
+	nHeader, err := w.Write(header)
+	nTotal := int64(nHeader)
+
+	// Write each element to w
+	for i := 0; err == nil && i < length; i++ {
+		var currentN int64
+		currentN, err = a[i].WriteTo(w)
+		nTotal += currentN
+	}
+
+	return nTotal, err
+}
+
+// ArrayHeader returns the MessagePack header (FixArray, Array16 or Array32) of a array
+// that contains length elements.
+func ArrayHeader(length int) ([]byte, error) {
+	var header []byte
 
 	switch {
 	case length < 1<<4:
@@ -56,18 +77,8 @@ func (a Array) WriteTo(w io.Writer) (int64, error) {
 		header[0] = Array32
 		binary.BigEndian.PutUint32(header[1:], uint32(length))
 	default:
-		return 0, utils.ExceededLengthError{Type: "Array", ActualLength: length}
-	}
-
-	nHeader, err := w.Write(header)
-	nTotal := int64(nHeader)
-
-	// Write each element to w
-	for i := 0; err == nil && i < length; i++ {
-		var currentN int64
-		currentN, err = a[i].WriteTo(w)
-		nTotal += currentN
+		return nil, utils.ExceededLengthError{Type: "Array", ActualLength: length}
 	}
 
-	return nTotal, err
+	return header, nil
 }
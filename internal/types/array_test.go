@@ -84,3 +84,28 @@ func TestArray_Len_ArrError1(t *testing.T) {
 		t.Error("Error was expected.")
 	}
 }
+
+func TestArrayHeader(t *testing.T) {
+	data := []struct {
+		length   int
+		expected []byte
+		name     string
+	}{
+		{length: 15, expected: []byte{0x9F}, name: "fixarray"},
+		{length: 16, expected: []byte{Array16, 0x00, 0x10}, name: "array16"},
+		{length: 1 << 16, expected: []byte{Array32, 0x00, 0x01, 0x00, 0x00}, name: "array32"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := ArrayHeader(test.length)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			if !bytes.Equal(result, test.expected) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result, test.expected)
+			}
+		})
+	}
+}
@@ -5,13 +5,18 @@ import (
 	"github.com/ErikPelli/sbor/internal/utils"
 	"io"
 	"math"
+	"strconv"
 )
 
 // Len returns the length of the MessagePack encoded external type.
 // It is 0 if the data inside is invalid.
 func (e External) Len() int {
-	length := len(e.Data)
+	return externalLen(len(e.Data))
+}
 
+// externalLen returns the length of an external type with a payload of the given length,
+// or 0 if the payload is too long.
+func externalLen(length int) int {
 	switch length {
 	case 1, 2, 4, 8, 16:
 		length += 2
@@ -34,8 +39,23 @@ func (e External) Len() int {
 // It implements io.WriterTo interface.
 // It returns the number of written bytes and an optional error.
 func (e External) WriteTo(w io.Writer) (int64, error) {
+	header, err := externalHeader(e.Type, len(e.Data))
+	if err != nil {
+		return 0, err
+	}
+
+	headerBytes, err := w.Write(header)
+	var dataBytes int
+	if err == nil {
+		dataBytes, err = w.Write(e.Data)
+	}
+
+	return int64(headerBytes + dataBytes), err
+}
+
+// externalHeader returns the header of an external type with a payload of the given length.
+func externalHeader(extType byte, length int) ([]byte, error) {
 	var header []byte
-	length := len(e.Data)
 
 	var fixExtTypeSet bool
 	var fixExtTypeCode byte
@@ -65,33 +85,78 @@ func (e External) WriteTo(w io.Writer) (int64, error) {
 		setFixCode(FixExt16)
 		header = make([]byte, 2)
 		header[0] = fixExtTypeCode
-		header[1] = e.Type
+		header[1] = extType
 
 	// Variable length external
 	case length <= math.MaxUint8:
 		header = make([]byte, 3)
 		header[0] = Ext8
 		header[1] = byte(length)
-		header[2] = e.Type
+		header[2] = extType
 	case length <= math.MaxUint16:
 		header = make([]byte, 4)
 		header[0] = Ext16
 		binary.BigEndian.PutUint16(header[1:], uint16(length))
-		header[3] = e.Type
+		header[3] = extType
 	case length <= math.MaxUint32:
 		header = make([]byte, 6)
 		header[0] = Ext32
 		binary.BigEndian.PutUint32(header[1:], uint32(length))
-		header[5] = e.Type
+		header[5] = extType
 	default:
-		return 0, utils.ExceededLengthError{Type: "External", ActualLength: length}
+		return nil, utils.ExceededLengthError{Type: "External", ActualLength: length}
+	}
+
+	return header, nil
+}
+
+// Len returns the length of the MessagePack encoded external type, using the declared size.
+// It is 0 if the size is invalid.
+func (s StreamExternal) Len() int {
+	if s.Size < 0 {
+		return 0
+	}
+	return externalLen(s.Size)
+}
+
+// WriteTo writes the header of the external type and then its payload, calling Write.
+// It implements io.WriterTo interface.
+// It returns the number of written bytes and an error if Write fails or it
+// doesn't write exactly the declared size.
+func (s StreamExternal) WriteTo(w io.Writer) (int64, error) {
+	if s.Size < 0 {
+		return 0, utils.InvalidTypeError{Type: "external payload with a negative size"}
+	}
+	header, err := externalHeader(s.Type, s.Size)
+	if err != nil {
+		return 0, err
 	}
 
 	headerBytes, err := w.Write(header)
-	var dataBytes int
-	if err == nil {
-		dataBytes, err = w.Write(e.Data)
+	if err != nil {
+		return int64(headerBytes), err
 	}
 
-	return int64(headerBytes + dataBytes), err
+	payload := &sizedWriter{w: w, remaining: s.Size}
+	err = s.Write(payload)
+	if err == nil && payload.remaining > 0 {
+		err = utils.InvalidTypeError{Type: "external payload shorter than the declared size " + strconv.Itoa(s.Size)}
+	}
+
+	return int64(headerBytes + s.Size - payload.remaining), err
+}
+
+// sizedWriter writes to w at most remaining bytes.
+type sizedWriter struct {
+	w         io.Writer
+	remaining int
+}
+
+func (s *sizedWriter) Write(p []byte) (int, error) {
+	if len(p) > s.remaining {
+		return 0, utils.InvalidTypeError{Type: "external payload longer than the declared size"}
+	}
+	n, err := s.w.Write(p)
+	s.remaining -= n
+	return n, err
 }
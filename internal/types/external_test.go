@@ -1,7 +1,10 @@
 package types
 
 import (
+	"bytes"
+	"errors"
 	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
 	"math/rand"
 	"testing"
 )
@@ -91,3 +94,62 @@ func TestExternal_WriteTo_ExtError(t *testing.T) {
 	}
 	utils.TypeWriteToTest(t, data, true)
 }
+
+func TestStreamExternal_WriteTo(t *testing.T) {
+	write := func(chunks ...[]byte) func(w io.Writer) error {
+		return func(w io.Writer) error {
+			for _, c := range chunks {
+				if _, err := w.Write(c); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	payload := make([]byte, 300)
+	rand.Read(payload)
+	expected := append([]byte{Ext16, 0x01, 0x2C, 0x20}, payload...)
+
+	data := []utils.WriteTestData{
+		{Input: StreamExternal{Type: 0x10, Size: 2, Write: write([]byte{0x01}, []byte{0x02})}, Expected: []byte{0xD5, 0x10, 0x01, 0x02}, Name: "FixExt2"},
+		{Input: StreamExternal{Type: 0x10, Size: 3, Write: write([]byte{0x01, 0x02, 0x03})}, Expected: []byte{Ext8, 0x03, 0x10, 0x01, 0x02, 0x03}, Name: "Ext8"},
+		{Input: StreamExternal{Type: 0x20, Size: 300, Write: write(payload[:100], payload[100:])}, Expected: expected, Name: "Ext16"},
+	}
+	utils.TypeWriteToTest(t, data)
+}
+
+func TestStreamExternal_WriteTo_Error(t *testing.T) {
+	writeErr := errors.New("write error")
+
+	data := []struct {
+		input StreamExternal
+		name  string
+	}{
+		{input: StreamExternal{Type: 0x10, Size: 2, Write: func(w io.Writer) error {
+			_, err := w.Write([]byte{0x01})
+			return err
+		}}, name: "Shorter"},
+		{input: StreamExternal{Type: 0x10, Size: 2, Write: func(w io.Writer) error {
+			_, err := w.Write([]byte{0x01, 0x02, 0x03})
+			return err
+		}}, name: "Longer"},
+		{input: StreamExternal{Type: 0x10, Size: 2, Write: func(w io.Writer) error {
+			return writeErr
+		}}, name: "Write error"},
+		{input: StreamExternal{Type: 0x10, Size: -1}, name: "Negative size"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			n, err := test.input.WriteTo(&b)
+			if err == nil {
+				t.Error("Error was expected.")
+			}
+			if int(n) != b.Len() {
+				t.Errorf("Invalid written bytes. Function returned %d. Expected %d.", n, b.Len())
+			}
+		})
+	}
+}
@@ -41,23 +41,10 @@ func (m Map) Len() int {
 // It implements io.WriterTo interface.
 // It returns the number of written bytes and an optional error.
 func (m Map) WriteTo(w io.Writer) (int64, error) {
-	var header []byte
 	length := len(m)
-
-	switch {
-	case length < 1<<4:
-		header = make([]byte, 1)
-		header[0] = FixMap | byte(length)
-	case length <= math.MaxUint16:
-		header = make([]byte, 3)
-		header[0] = Map16
-		binary.BigEndian.PutUint16(header[1:], uint16(length))
-	case length <= math.MaxUint32:
-		header = make([]byte, 5)
-		header[0] = Map32
-		binary.BigEndian.PutUint32(header[1:], uint32(length))
-	default:
-		return 0, utils.ExceededLengthError{Type: "Map", ActualLength: length}
+	header, err := MapHeader(length)
+	if err != nil {
+		return 0, err
 	}
 
 	nHeader, err := w.Write(header)
@@ -78,3 +65,27 @@ func (m Map) WriteTo(w io.Writer) (int64, error) {
 
 	return nTotal, err
 }
+
+// MapHeader returns the MessagePack header (FixMap, Map16 or Map32) of a map
+// that contains length elements.
+func MapHeader(length int) ([]byte, error) {
+	var header []byte
+
+	switch {
+	case length < 1<<4:
+		header = make([]byte, 1)
+		header[0] = FixMap | byte(length)
+	case length <= math.MaxUint16:
+		header = make([]byte, 3)
+		header[0] = Map16
+		binary.BigEndian.PutUint16(header[1:], uint16(length))
+	case length <= math.MaxUint32:
+		header = make([]byte, 5)
+		header[0] = Map32
+		binary.BigEndian.PutUint32(header[1:], uint32(length))
+	default:
+		return nil, utils.ExceededLengthError{Type: "Map", ActualLength: length}
+	}
+
+	return header, nil
+}
@@ -235,3 +235,28 @@ func TestArray_Len_MapError1(t *testing.T) {
 		t.Error("Error was expected.")
 	}
 }
+
+func TestMapHeader(t *testing.T) {
+	data := []struct {
+		length   int
+		expected []byte
+		name     string
+	}{
+		{length: 15, expected: []byte{0x8F}, name: "fixmap"},
+		{length: 16, expected: []byte{Map16, 0x00, 0x10}, name: "map16"},
+		{length: 1 << 16, expected: []byte{Map32, 0x00, 0x01, 0x00, 0x00}, name: "map32"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := MapHeader(test.length)
+			if err != nil {
+				t.Error(err.Error())
+			}
+
+			if !bytes.Equal(result, test.expected) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result, test.expected)
+			}
+		})
+	}
+}
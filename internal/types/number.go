@@ -2,8 +2,10 @@ package types
 
 import (
 	"encoding/binary"
+	"github.com/ErikPelli/sbor/internal/utils"
 	"io"
 	"math"
+	"strconv"
 )
 
 // Len returns the length of the MessagePack encoded integer.
@@ -149,3 +151,93 @@ func (f Float) WriteTo(w io.Writer) (int64, error) {
 	writtenBytes, err := w.Write(bytes)
 	return int64(writtenBytes), err
 }
+
+// Len returns the length of the MessagePack encoded integer.
+func (i FixedInt) Len() int {
+	return 1 + i.Size
+}
+
+// WriteTo writes the encoding of the integer value to io.Writer.
+// It implements io.WriterTo interface.
+// It returns the number of written bytes and an optional error.
+func (i FixedInt) WriteTo(w io.Writer) (int64, error) {
+	bytes := make([]byte, i.Len())
+
+	switch i.Size {
+	case 1:
+		bytes[0] = Int8
+		bytes[1] = byte(i.Value)
+	case 2:
+		bytes[0] = Int16
+		binary.BigEndian.PutUint16(bytes[1:], uint16(i.Value))
+	case 4:
+		bytes[0] = Int32
+		binary.BigEndian.PutUint32(bytes[1:], uint32(i.Value))
+	case 8:
+		bytes[0] = Int64
+		binary.BigEndian.PutUint64(bytes[1:], uint64(i.Value))
+	default:
+		return 0, utils.InvalidTypeError{Type: "int of " + strconv.Itoa(i.Size) + " bytes"}
+	}
+
+	writtenBytes, err := w.Write(bytes)
+	return int64(writtenBytes), err
+}
+
+// Len returns the length of the MessagePack encoded unsigned integer.
+func (u FixedUint) Len() int {
+	return 1 + u.Size
+}
+
+// WriteTo writes the encoding of the unsigned integer value to io.Writer.
+// It implements io.WriterTo interface.
+// It returns the number of written bytes and an optional error.
+func (u FixedUint) WriteTo(w io.Writer) (int64, error) {
+	bytes := make([]byte, u.Len())
+
+	switch u.Size {
+	case 1:
+		bytes[0] = Uint8
+		bytes[1] = byte(u.Value)
+	case 2:
+		bytes[0] = Uint16
+		binary.BigEndian.PutUint16(bytes[1:], uint16(u.Value))
+	case 4:
+		bytes[0] = Uint32
+		binary.BigEndian.PutUint32(bytes[1:], uint32(u.Value))
+	case 8:
+		bytes[0] = Uint64
+		binary.BigEndian.PutUint64(bytes[1:], u.Value)
+	default:
+		return 0, utils.InvalidTypeError{Type: "uint of " + strconv.Itoa(u.Size) + " bytes"}
+	}
+
+	writtenBytes, err := w.Write(bytes)
+	return int64(writtenBytes), err
+}
+
+// Len returns the length of the MessagePack encoded float.
+func (f FixedFloat) Len() int {
+	return 1 + f.Size
+}
+
+// WriteTo writes the encoding of the floating point value to io.Writer.
+// It implements io.WriterTo interface.
+// It returns the number of written bytes and an optional error.
+func (f FixedFloat) WriteTo(w io.Writer) (int64, error) {
+	bytes := make([]byte, f.Len())
+
+	switch f.Size {
+	case 4:
+		bytes[0] = Float32
+		binary.BigEndian.PutUint32(bytes[1:], math.Float32bits(float32(f.Value)))
+	case 8:
+		bytes[0] = Float64
+		binary.BigEndian.PutUint64(bytes[1:], math.Float64bits(f.Value))
+	default:
+		return 0, utils.InvalidTypeError{Type: "float of " + strconv.Itoa(f.Size) + " bytes"}
+	}
+
+	writtenBytes, err := w.Write(bytes)
+	return int64(writtenBytes), err
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
 	"testing"
 )
 
@@ -37,3 +38,47 @@ func TestFloat_WriteTo(t *testing.T) {
 	}
 	utils.TypeWriteToTest(t, data)
 }
+
+func TestFixedInt_WriteTo(t *testing.T) {
+	data := []utils.WriteTestData{
+		{Input: FixedInt{Value: 5, Size: 1}, Expected: []byte{Int8, 0x05}, Name: "int8"},
+		{Input: FixedInt{Value: -1, Size: 2}, Expected: []byte{Int16, 0xFF, 0xFF}, Name: "int16"},
+		{Input: FixedInt{Value: 1, Size: 4}, Expected: []byte{Int32, 0x00, 0x00, 0x00, 0x01}, Name: "int32"},
+		{Input: FixedInt{Value: 0, Size: 8}, Expected: []byte{Int64, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, Name: "int64"},
+	}
+	utils.TypeWriteToTest(t, data)
+}
+
+func TestFixedUint_WriteTo(t *testing.T) {
+	data := []utils.WriteTestData{
+		{Input: FixedUint{Value: 5, Size: 1}, Expected: []byte{Uint8, 0x05}, Name: "uint8"},
+		{Input: FixedUint{Value: 1, Size: 2}, Expected: []byte{Uint16, 0x00, 0x01}, Name: "uint16"},
+		{Input: FixedUint{Value: 1, Size: 4}, Expected: []byte{Uint32, 0x00, 0x00, 0x00, 0x01}, Name: "uint32"},
+		{Input: FixedUint{Value: 1 << 63, Size: 8}, Expected: []byte{Uint64, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, Name: "uint64"},
+	}
+	utils.TypeWriteToTest(t, data)
+}
+
+func TestFixedFloat_WriteTo(t *testing.T) {
+	data := []utils.WriteTestData{
+		{Input: FixedFloat{Value: 9.5, Size: 8}, Expected: []byte{Float64, 0x40, 0x23, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, Name: "float64"},
+		{Input: FixedFloat{Value: 9.5, Size: 4}, Expected: []byte{Float32, 0x41, 0x18, 0x00, 0x00}, Name: "float32"},
+	}
+	utils.TypeWriteToTest(t, data)
+}
+
+func TestFixed_WriteTo_Error(t *testing.T) {
+	data := []utils.WriteTestData{
+		{Input: FixedInt{Value: 1, Size: 3}, Expected: []byte{}, Name: "int"},
+		{Input: FixedUint{Value: 1, Size: 16}, Expected: []byte{}, Name: "uint"},
+		{Input: FixedFloat{Value: 1, Size: 2}, Expected: []byte{}, Name: "float"},
+	}
+
+	for _, test := range data {
+		t.Run(test.Name, func(t *testing.T) {
+			if _, err := test.Input.WriteTo(io.Discard); err == nil {
+				t.Error("Error was expected.")
+			}
+		})
+	}
+}
@@ -35,3 +35,16 @@ func (b Boolean) WriteTo(w io.Writer) (int64, error) {
 	writtenBytes, err := w.Write([]byte{value})
 	return int64(writtenBytes), err
 }
+
+// Len returns the length of the already encoded MessagePack object.
+func (e Encoded) Len() int {
+	return len(e)
+}
+
+// WriteTo writes the already encoded object to io.Writer, as it is.
+// It implements io.WriterTo interface.
+// It returns the number of written bytes and an optional error.
+func (e Encoded) WriteTo(w io.Writer) (int64, error) {
+	writtenBytes, err := w.Write(e)
+	return int64(writtenBytes), err
+}
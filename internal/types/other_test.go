@@ -19,3 +19,10 @@ func TestBoolean_WriteTo(t *testing.T) {
 	}
 	utils.TypeWriteToTest(t, data)
 }
+
+func TestEncoded_WriteTo(t *testing.T) {
+	data := []utils.WriteTestData{
+		{Input: Encoded{0x92, 0xC3, 0xC0}, Expected: []byte{0x92, 0xC3, 0xC0}},
+	}
+	utils.TypeWriteToTest(t, data)
+}
@@ -132,3 +132,17 @@ func TestString_WriteTo_BinError(t *testing.T) {
 	}
 	utils.TypeWriteToTest(t, data, true)
 }
+
+func TestRawString_WriteTo(t *testing.T) {
+	str16 := make([]byte, 3+130)
+	str16[0], str16[1], str16[2] = Str16, 0x00, 130
+	for i := 3; i < len(str16); i++ {
+		str16[i] = 0x23
+	}
+
+	data := []utils.WriteTestData{
+		{Input: RawString("hi"), Expected: []byte{0xA2, 0x68, 0x69}, Name: "fixstr"},
+		{Input: RawString(strings.Repeat("#", 130)), Expected: str16, Name: "str16 instead of str8"},
+	}
+	utils.TypeWriteToTest(t, data)
+}
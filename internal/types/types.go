@@ -2,6 +2,7 @@ package types
 
 import (
 	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
 	"reflect"
 )
 
@@ -71,6 +72,11 @@ type (
 	Array   []utils.MessagePackType
 	Map     []MessagePackMap
 	Struct  reflect.Value
+	Encoded []byte
+
+	// RawString is a string of the old MessagePack specification, written
+	// only with the fixstr, str16 and str32 formats (fixraw, raw16 and raw32).
+	RawString string
 )
 
 // External MessagePack type
@@ -79,6 +85,35 @@ type External struct {
 	Data []byte
 }
 
+// StreamExternal is a MessagePack External whose payload of Size bytes is written
+// by Write directly to the output, after the header.
+type StreamExternal struct {
+	Type  byte
+	Size  int
+	Write func(w io.Writer) error
+}
+
+// FixedInt is an integer written with the int format of the given size in bytes
+// (1, 2, 4 or 8), that must be able to contain the value.
+type FixedInt struct {
+	Value int64
+	Size  int
+}
+
+// FixedUint is an unsigned integer written with the uint format of the given size
+// in bytes (1, 2, 4 or 8), that must be able to contain the value.
+type FixedUint struct {
+	Value uint64
+	Size  int
+}
+
+// FixedFloat is a floating point number written with the float format of the
+// given size in bytes (4 or 8).
+type FixedFloat struct {
+	Value float64
+	Size  int
+}
+
 // MessagePackMap is a single Key-Value association
 type MessagePackMap struct {
 	Key   utils.MessagePackType
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"io"
+	"reflect"
 	"strconv"
 )
 
@@ -34,11 +35,17 @@ func (e ExceededLengthError) Error() string {
 	return e.Type + " exceeded max length (len: " + strconv.Itoa(e.ActualLength) + ")"
 }
 
+// DuplicatedKeyError describes a key used more than once. Offset is the offset of the
+// repeated key in the MessagePack data when decoding, and zero otherwise.
 type DuplicatedKeyError struct {
-	Key interface{}
+	Key    interface{}
+	Offset int
 }
 
 func (d DuplicatedKeyError) Error() string {
+	if d.Offset > 0 {
+		return fmt.Sprintf("Duplicated key %v at offset %d", d.Key, d.Offset)
+	}
 	return fmt.Sprintf("Duplicated key %v", d.Key)
 }
 
@@ -57,3 +64,64 @@ type InvalidArgumentError struct {
 func (i InvalidArgumentError) Error() string {
 	return "Invalid argument: " + i.Desc
 }
+
+type SyntaxError struct {
+	Offset int
+	Desc   string
+}
+
+func (s SyntaxError) Error() string {
+	return "Invalid MessagePack data at offset " + strconv.Itoa(s.Offset) + ": " + s.Desc
+}
+
+type NotFoundError struct {
+	Key interface{}
+}
+
+func (n NotFoundError) Error() string {
+	return fmt.Sprintf("Key %v not found", n.Key)
+}
+
+type DiagSyntaxError struct {
+	Offset int
+	Desc   string
+}
+
+func (d DiagSyntaxError) Error() string {
+	return "Invalid diagnostic notation at offset " + strconv.Itoa(d.Offset) + ": " + d.Desc
+}
+
+// UnknownFieldError describes a map key that doesn't match any field of a Go struct,
+// when the unknown fields are disallowed.
+type UnknownFieldError struct {
+	Key    interface{}
+	Type   reflect.Type // Type of the Go struct
+	Offset int
+}
+
+func (u UnknownFieldError) Error() string {
+	return fmt.Sprintf("Unknown field %v of Go struct %s at offset %d", u.Key, u.Type, u.Offset)
+}
+
+// MissingFieldError describes a required field of a Go struct that is absent
+// from a MessagePack map or array. Offset is the offset of the map or array.
+type MissingFieldError struct {
+	Field  string
+	Type   reflect.Type // Type of the Go struct
+	Offset int
+}
+
+func (m MissingFieldError) Error() string {
+	return "Missing required field " + m.Field + " of Go struct " + m.Type.String() + " at offset " + strconv.Itoa(m.Offset)
+}
+
+type UnmarshalTypeError struct {
+	Value  string       // Description of the MessagePack value, like "str" or "int 300"
+	Type   reflect.Type // Type of the Go value it could not be assigned to
+	Offset int
+}
+
+func (u UnmarshalTypeError) Error() string {
+	return "Cannot unmarshal MessagePack " + u.Value + " into Go value of type " + u.Type.String() +
+		" at offset " + strconv.Itoa(u.Offset)
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 )
 
@@ -36,6 +37,11 @@ func TestDuplicatedKeyError(t *testing.T) {
 	if errT.Error() == "" {
 		t.Errorf("Empty error. Error: %v", errT)
 	}
+
+	errT = DuplicatedKeyError{Key: "a", Offset: 7}
+	if errT.Error() != "Duplicated key a at offset 7" {
+		t.Errorf("Invalid error. Error: %v", errT)
+	}
 }
 
 func TestOutOfBoundError(t *testing.T) {
@@ -51,3 +57,45 @@ func TestInvalidArgumentError(t *testing.T) {
 		t.Errorf("Empty error. Error: %v", errT)
 	}
 }
+
+func TestSyntaxError(t *testing.T) {
+	errT := SyntaxError{Offset: 4, Desc: "test"}
+	if errT.Error() == "" {
+		t.Errorf("Empty error. Error: %v", errT)
+	}
+}
+
+func TestNotFoundError(t *testing.T) {
+	errT := NotFoundError{Key: "tenant"}
+	if errT.Error() == "" {
+		t.Errorf("Empty error. Error: %v", errT)
+	}
+}
+
+func TestUnknownFieldError(t *testing.T) {
+	errT := UnknownFieldError{Key: "name", Type: reflect.TypeOf(struct{}{}), Offset: 3}
+	if errT.Error() == "" {
+		t.Errorf("Empty error. Error: %v", errT)
+	}
+}
+
+func TestMissingFieldError(t *testing.T) {
+	errT := MissingFieldError{Field: "name", Type: reflect.TypeOf(struct{}{}), Offset: 0}
+	if errT.Error() == "" {
+		t.Errorf("Empty error. Error: %v", errT)
+	}
+}
+
+func TestDiagSyntaxError(t *testing.T) {
+	errT := DiagSyntaxError{Offset: 4, Desc: "test"}
+	if errT.Error() == "" {
+		t.Errorf("Empty error. Error: %v", errT)
+	}
+}
+
+func TestUnmarshalTypeError(t *testing.T) {
+	errT := UnmarshalTypeError{Value: "str", Type: reflect.TypeOf(0), Offset: 4}
+	if errT.Error() == "" {
+		t.Errorf("Empty error. Error: %v", errT)
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"reflect"
 	"strings"
 )
 
@@ -17,6 +18,35 @@ func ParseTag(tag string) (string, Options) {
 	return tag, ""
 }
 
+// Value returns the value of the option in the form name=value, and whether it's present.
+func (o Options) Value(name string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var next string
+		i := strings.Index(s, ",")
+		if i >= 0 {
+			s, next = s[:i], s[i+1:]
+		}
+		if strings.HasPrefix(s, name+"=") {
+			return s[len(name)+1:], true
+		}
+		s = next
+	}
+	return "", false
+}
+
+// LookupTag returns the value of the first key of names that is present in the
+// struct field's tag, and whether one was found.
+// The options of different keys are never merged.
+func LookupTag(tag reflect.StructTag, names []string) (string, bool) {
+	for _, name := range names {
+		if value, ok := tag.Lookup(name); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 // Contains reports whether a comma-separated list of options
 // contains a particular substr flag. substr must be surrounded by a
 // string boundary or commas.
@@ -38,3 +68,19 @@ func (o Options) Contains(optionName string) bool {
 	}
 	return false
 }
+
+// Quotable reports whether the "string" option applies to a struct field of type t,
+// that is a boolean, an integer or a floating point number, or a pointer to one of them.
+func Quotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
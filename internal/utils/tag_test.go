@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -44,3 +45,61 @@ func TestOptionNotFound(t *testing.T) {
 		t.Error("Option should not be found.")
 	}
 }
+
+func TestOptionValue(t *testing.T) {
+	_, options := ParseTag("name,omitempty,float=64,int=")
+	if value, ok := options.Value("float"); !ok || value != "64" {
+		t.Errorf("Invalid result. Function returned %q, %v. Expected %q, %v.", value, ok, "64", true)
+	}
+	if value, ok := options.Value("int"); !ok || value != "" {
+		t.Errorf("Invalid result. Function returned %q, %v. Expected %q, %v.", value, ok, "", true)
+	}
+	if _, ok := options.Value("omitempty"); ok {
+		t.Error("Option without value should not be found.")
+	}
+}
+
+func TestLookupTag(t *testing.T) {
+	data := []struct {
+		tag      reflect.StructTag
+		names    []string
+		expected string
+		found    bool
+	}{
+		{`sbor:"a" json:"b"`, []string{"sbor", "json"}, "a", true},
+		{`json:"b,omitempty"`, []string{"sbor", "msgpack", "json"}, "b,omitempty", true},
+		{`sbor:"" json:"b"`, []string{"sbor", "json"}, "", true},
+		{`json:"b"`, []string{"sbor"}, "", false},
+		{`sbor:"a"`, nil, "", false},
+	}
+
+	for _, test := range data {
+		value, found := LookupTag(test.tag, test.names)
+		if value != test.expected || found != test.found {
+			t.Errorf("Invalid result. Function returned %q, %v. Expected %q, %v.", value, found, test.expected, test.found)
+		}
+	}
+}
+
+func TestQuotable(t *testing.T) {
+	data := []struct {
+		value    interface{}
+		expected bool
+	}{
+		{true, true},
+		{int8(1), true},
+		{uint64(1), true},
+		{1.5, true},
+		{new(float32), true},
+		{"text", false},
+		{uintptr(1), false},
+		{new(*int), false},
+		{[]int{1}, false},
+	}
+
+	for _, test := range data {
+		if result := Quotable(reflect.TypeOf(test.value)); result != test.expected {
+			t.Errorf("Invalid result for %T. Function returned %v. Expected %v.", test.value, result, test.expected)
+		}
+	}
+}
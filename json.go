@@ -0,0 +1,570 @@
+package sbor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSafeInteger is the biggest integer that a JSON number can represent
+// without loss of precision in most of the implementations.
+const maxSafeInteger = 1 << 53
+
+// jsonNumberTags are the tags used for the numbers that need a specific format.
+var jsonNumberTags = map[string]byte{
+	"$uint8":   types.Uint8,
+	"$uint16":  types.Uint16,
+	"$uint32":  types.Uint32,
+	"$uint64":  types.Uint64,
+	"$int8":    types.Int8,
+	"$int16":   types.Int16,
+	"$int32":   types.Int32,
+	"$int64":   types.Int64,
+	"$float32": types.Float32,
+	"$float64": types.Float64,
+}
+
+// isJSONTag reports whether key is the key of a tagged object.
+func isJSONTag(key string) bool {
+	switch key {
+	case "$bin", "$ext", "$time", "$map", "$diag":
+		return true
+	}
+	_, ok := jsonNumberTags[key]
+	return ok
+}
+
+// ToJSON writes the JSON representation of the MessagePack objects concatenated in data
+// to w, one per line, without decoding them to Go values. FromJSON converts the output
+// back to the same bytes, using the following convention for what JSON lacks.
+//
+// The objects that use the format chosen by the encoder for their value are written as
+// plain JSON: nil, booleans, strings, arrays and maps with string keys, integers between
+// -2^53 and 2^53 in the smallest int format (the one of a Go int), and floats with a
+// decimal point or an exponent. The other objects are written as a JSON object with a
+// single tag key:
+//
+//   {"$bin": "zP8="}                       bin, in base64
+//   {"$ext": [16, "dGVzdA=="]}             ext with its type, in base64
+//   {"$time": "2022-03-06T15:20:00Z"}      timestamp (ext type -1), in RFC 3339 for the years 0 ... 9999
+//   {"$map": [[1, "a"], [[2], "b"]]}       map with keys that aren't strings, as key-value pairs
+//   {"$uint16": 1}, {"$int64": "-9007199254740993"}
+//                                          integer in a specific format, in a string beyond 2^53
+//   {"$float64": 1.5}, {"$float32": "NaN"} float in a specific format, NaN and Infinity in a string
+//   {"$diag": "\"a\"_8"}                   any other object, in the diagnostic notation of ParseDiag
+//
+// A map with a single string key that starts with "$" is written as a $map, so that it
+// can't be confused with a tag. $diag is used for the strings that aren't valid UTF-8,
+// for the objects with a length field bigger than needed and for the NaNs with a payload.
+//
+// If data isn't valid, a SyntaxError is returned.
+func ToJSON(w io.Writer, data []byte) error {
+	j := jsonWriter{w: bufio.NewWriter(w), data: data}
+
+	var err error
+	for offset := 0; offset < len(data) && err == nil; {
+		// Check the whole object before writing it
+		if _, err = decode.Skip(data, offset); err == nil {
+			offset, err = j.value(offset, 0)
+			j.w.WriteByte('\n')
+		}
+	}
+
+	if flushErr := j.w.Flush(); err == nil {
+		err = flushErr
+	}
+	return err
+}
+
+type jsonWriter struct {
+	w    *bufio.Writer
+	data []byte
+}
+
+// value writes the JSON representation of the valid object that starts at data[offset],
+// and returns the offset of the first byte after it.
+func (j *jsonWriter) value(offset int, depth int) (int, error) {
+	h, _ := decode.ReadHeader(j.data, offset)
+	end := offset + h.Size + h.Payload()
+	payload := j.data[offset+h.Size : end]
+
+	if !canonical(j.data, offset, h) && h.Kind != decode.Int && h.Kind != decode.Uint && h.Kind != decode.Float {
+		return j.diag(offset)
+	}
+
+	switch h.Kind {
+	case decode.Nil:
+		j.w.WriteString("null")
+	case decode.Boolean:
+		j.w.WriteString(strconv.FormatBool(h.Code == types.True))
+	case decode.Int, decode.Uint:
+		j.integer(offset, h)
+	case decode.Float:
+		if exactNaN(h, payload) {
+			return j.diag(offset)
+		}
+		j.float(offset, h)
+
+	case decode.String:
+		if !utf8.Valid(payload) {
+			return j.diag(offset)
+		}
+		writeJSONString(j.w, string(payload))
+
+	case decode.Binary:
+		j.w.WriteString(`{"$bin":"`)
+		j.w.WriteString(base64.StdEncoding.EncodeToString(payload))
+		j.w.WriteString(`"}`)
+
+	case decode.Ext:
+		if t, ok := rfc3339Timestamp(h, payload); ok {
+			j.w.WriteString(`{"$time":"` + t.Format(time.RFC3339Nano) + `"}`)
+		} else {
+			j.w.WriteString(`{"$ext":[` + strconv.Itoa(int(h.ExtType)) + `,"`)
+			j.w.WriteString(base64.StdEncoding.EncodeToString(payload))
+			j.w.WriteString(`"]}`)
+		}
+
+	default:
+		if depth >= decode.MaxDepth {
+			return offset, utils.SyntaxError{Offset: offset, Desc: "exceeded max nesting depth"}
+		}
+		return j.container(offset, h, depth)
+	}
+
+	return end, nil
+}
+
+// container writes the JSON representation of the valid array or map with header h
+// that starts at data[offset], and returns the offset of the first byte after it.
+func (j *jsonWriter) container(offset int, h decode.Header, depth int) (int, error) {
+	end := offset + h.Size
+	var err error
+
+	if h.Kind == decode.Array {
+		j.w.WriteByte('[')
+		for i := 0; i < h.Length; i++ {
+			if i > 0 {
+				j.w.WriteByte(',')
+			}
+			if end, err = j.value(end, depth+1); err != nil {
+				return end, err
+			}
+		}
+		j.w.WriteByte(']')
+		return end, nil
+	}
+
+	if !j.stringKeys(end, h.Length) {
+		j.w.WriteString(`{"$map":[`)
+		for i := 0; i < h.Length; i++ {
+			if i > 0 {
+				j.w.WriteByte(',')
+			}
+			j.w.WriteByte('[')
+			if end, err = j.value(end, depth+1); err != nil {
+				return end, err
+			}
+			j.w.WriteByte(',')
+			if end, err = j.value(end, depth+1); err != nil {
+				return end, err
+			}
+			j.w.WriteByte(']')
+		}
+		j.w.WriteString("]}")
+		return end, nil
+	}
+
+	j.w.WriteByte('{')
+	for i := 0; i < h.Length; i++ {
+		if i > 0 {
+			j.w.WriteByte(',')
+		}
+		if end, err = j.value(end, depth+1); err != nil {
+			return end, err
+		}
+		j.w.WriteByte(':')
+		if end, err = j.value(end, depth+1); err != nil {
+			return end, err
+		}
+	}
+	j.w.WriteByte('}')
+	return end, nil
+}
+
+// stringKeys reports whether all the keys of the valid map with length pairs, that start
+// at data[offset], can be written as the keys of a plain JSON object.
+func (j *jsonWriter) stringKeys(offset int, length int) bool {
+	for i := 0; i < length; i++ {
+		h, _ := decode.ReadHeader(j.data, offset)
+		key := j.data[offset+h.Size : offset+h.Size+h.Payload()]
+
+		if h.Kind != decode.String || !canonical(j.data, offset, h) || !utf8.Valid(key) {
+			return false
+		}
+		if length == 1 && bytes.HasPrefix(key, []byte{'$'}) {
+			// It would be confused with a tag
+			return false
+		}
+
+		offset, _ = decode.Skip(j.data, offset+h.Size+h.Payload())
+	}
+	return true
+}
+
+// integer writes the valid int or uint object with header h that starts at data[offset].
+func (j *jsonWriter) integer(offset int, h decode.Header) {
+	var text string
+	var safe, plain bool
+
+	if h.Kind == decode.Uint {
+		u := decode.ReadUint(j.data, offset, h)
+		text = strconv.FormatUint(u, 10)
+		safe = u <= maxSafeInteger
+		plain = safe && AppendInt(nil, int64(u))[0] == h.Code
+	} else {
+		i := decode.ReadInt(j.data, offset, h)
+		text = strconv.FormatInt(i, 10)
+		safe = i >= -maxSafeInteger && i <= maxSafeInteger
+		plain = safe && AppendInt(nil, i)[0] == h.Code
+	}
+
+	switch {
+	case plain:
+		j.w.WriteString(text)
+	case safe:
+		j.w.WriteString(`{"$` + decode.FormatName(h.Code) + `":` + text + `}`)
+	default:
+		j.w.WriteString(`{"$` + decode.FormatName(h.Code) + `":"` + text + `"}`)
+	}
+}
+
+// float writes the valid float object with header h that starts at data[offset].
+func (j *jsonWriter) float(offset int, h decode.Header) {
+	f := decode.ReadFloat(j.data, offset, h)
+	text := formatFloat(f, floatBitSize(h))
+
+	switch {
+	case math.IsNaN(f) || math.IsInf(f, 0):
+		j.w.WriteString(`{"$` + decode.FormatName(h.Code) + `":"` + text + `"}`)
+	case canonical(j.data, offset, h):
+		j.w.WriteString(text)
+	default:
+		j.w.WriteString(`{"$` + decode.FormatName(h.Code) + `":` + text + `}`)
+	}
+}
+
+// diag writes the valid object that starts at data[offset] as a $diag tag,
+// and returns the offset of the first byte after it.
+func (j *jsonWriter) diag(offset int) (int, error) {
+	end, err := decode.Skip(j.data, offset)
+	if err != nil {
+		return offset, err
+	}
+
+	var b bytes.Buffer
+	if err = Dump(&b, j.data[offset:end], DumpOptions{Compact: true}); err != nil {
+		return offset, err
+	}
+
+	j.w.WriteString(`{"$diag":`)
+	writeJSONString(j.w, strings.TrimSuffix(b.String(), "\n"))
+	j.w.WriteByte('}')
+	return end, nil
+}
+
+// writeJSONString writes s as a JSON string, without escaping the HTML characters.
+func writeJSONString(w *bufio.Writer, s string) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	w.Write(bytes.TrimSuffix(b.Bytes(), []byte{'\n'}))
+}
+
+// FromJSON reads a sequence of JSON values from r, and writes their MessagePack encoding
+// to w, without decoding them to Go values. Every value is written as soon as it's read.
+//
+// The tagged objects described in ToJSON are converted to the objects they represent,
+// so that the output of ToJSON is converted back to the same bytes. The keys of the other
+// objects keep their order. Integers between math.MinInt64 and math.MaxUint64 use the
+// smallest int format, or the smallest uint one beyond math.MaxInt64, while the other
+// numbers use the smallest float format that represents them without loss of precision.
+func FromJSON(w io.Writer, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		b, err := jsonValue(dec, token, nil, 0)
+		if err != nil {
+			return err
+		}
+		if _, err = w.Write(b); err != nil {
+			return err
+		}
+	}
+}
+
+// jsonToken returns the next JSON token of a value that isn't complete.
+func jsonToken(dec *json.Decoder) (json.Token, error) {
+	token, err := dec.Token()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return token, err
+}
+
+// jsonValue appends to b the encoding of the JSON value that starts with token.
+func jsonValue(dec *json.Decoder, token json.Token, b []byte, depth int) ([]byte, error) {
+	switch t := token.(type) {
+	case nil:
+		return append(b, types.NilCode), nil
+	case bool:
+		return appendType(b, types.Boolean(t))
+	case string:
+		return appendType(b, types.String(t))
+	case json.Number:
+		return appendJSONNumber(b, string(t))
+	}
+
+	if depth >= decode.MaxDepth {
+		return nil, utils.InvalidArgumentError{Desc: "exceeded max nesting depth"}
+	}
+
+	var body []byte
+	var length int
+
+	if token == json.Delim('[') {
+		for dec.More() {
+			next, err := jsonToken(dec)
+			if err != nil {
+				return nil, err
+			}
+			if body, err = jsonValue(dec, next, body, depth+1); err != nil {
+				return nil, err
+			}
+			length++
+		}
+		if _, err := jsonToken(dec); err != nil {
+			return nil, err
+		}
+
+		header, err := types.ArrayHeader(length)
+		if err != nil {
+			return nil, err
+		}
+		return append(append(b, header...), body...), nil
+	}
+
+	for dec.More() {
+		key, err := jsonToken(dec)
+		if err != nil {
+			return nil, err
+		}
+		body, _ = appendType(body, types.String(key.(string)))
+
+		if length == 0 && isJSONTag(key.(string)) {
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return nil, err
+			}
+			if !dec.More() {
+				if _, err = jsonToken(dec); err != nil {
+					return nil, err
+				}
+				return appendJSONTag(b, key.(string), raw, depth)
+			}
+
+			// A map with more keys
+			if body, err = appendRawJSON(body, raw, depth+1); err != nil {
+				return nil, err
+			}
+		} else {
+			next, err := jsonToken(dec)
+			if err != nil {
+				return nil, err
+			}
+			if body, err = jsonValue(dec, next, body, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		length++
+	}
+	if _, err := jsonToken(dec); err != nil {
+		return nil, err
+	}
+
+	header, err := types.MapHeader(length)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(b, header...), body...), nil
+}
+
+// appendRawJSON appends to b the encoding of the JSON value in raw.
+func appendRawJSON(b []byte, raw json.RawMessage, depth int) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	token, err := jsonToken(dec)
+	if err != nil {
+		return nil, err
+	}
+	return jsonValue(dec, token, b, depth)
+}
+
+// appendJSONNumber appends to b the encoding of the JSON number s.
+func appendJSONNumber(b []byte, s string) ([]byte, error) {
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return AppendInt(b, i), nil
+		}
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return AppendUint(b, u), nil
+		}
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, utils.InvalidArgumentError{Desc: "invalid JSON number " + s}
+	}
+	return AppendFloat(b, f), nil
+}
+
+// appendJSONTag appends to b the encoding of the object represented by the tag with the given value.
+func appendJSONTag(b []byte, tag string, raw json.RawMessage, depth int) ([]byte, error) {
+	invalid := utils.InvalidArgumentError{Desc: "invalid " + tag + " value " + string(raw)}
+
+	if code, ok := jsonNumberTags[tag]; ok {
+		// The value is a number, or a string for the big integers and the special floats
+		text := string(raw)
+		if s := ""; json.Unmarshal(raw, &s) == nil {
+			text = s
+		}
+
+		var bits uint64
+		var size int
+		var err error
+
+		switch {
+		case code >= types.Uint8 && code <= types.Uint64:
+			size = 1 << (code - types.Uint8)
+			bits, err = strconv.ParseUint(text, 10, 8*size)
+		case code >= types.Int8 && code <= types.Int64:
+			size = 1 << (code - types.Int8)
+			var i int64
+			i, err = strconv.ParseInt(text, 10, 8*size)
+			bits = uint64(i)
+		case code == types.Float32:
+			size = 4
+			var f float64
+			f, err = parseFloat(text, 32)
+			bits = uint64(math.Float32bits(float32(f)))
+		default:
+			size = 8
+			var f float64
+			f, err = parseFloat(text, 64)
+			bits = math.Float64bits(f)
+		}
+
+		if err != nil {
+			return nil, invalid
+		}
+		return appendFixed(b, code, bits, size), nil
+	}
+
+	switch tag {
+	case "$bin":
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, invalid
+		}
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, invalid
+		}
+		return appendType(b, types.Binary(data))
+
+	case "$ext":
+		var fields []json.RawMessage
+		var extType int8
+		var s string
+		if json.Unmarshal(raw, &fields) != nil || len(fields) != 2 ||
+			json.Unmarshal(fields[0], &extType) != nil || json.Unmarshal(fields[1], &s) != nil {
+			return nil, invalid
+		}
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, invalid
+		}
+		return appendType(b, types.External{Type: byte(extType), Data: data})
+
+	case "$time":
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, invalid
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, invalid
+		}
+		return appendType(b, encode.NewTimestamp(t))
+
+	case "$map":
+		var pairs [][]json.RawMessage
+		if err := json.Unmarshal(raw, &pairs); err != nil {
+			return nil, invalid
+		}
+		header, err := types.MapHeader(len(pairs))
+		if err != nil {
+			return nil, err
+		}
+
+		b = append(b, header...)
+		for _, pair := range pairs {
+			if len(pair) != 2 {
+				return nil, invalid
+			}
+			if b, err = appendRawJSON(b, pair[0], depth+1); err != nil {
+				return nil, err
+			}
+			if b, err = appendRawJSON(b, pair[1], depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+
+	default:
+		// $diag
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, invalid
+		}
+		encoded, err := ParseDiag(s)
+		if err != nil {
+			return nil, err
+		}
+		if end, _ := decode.Skip(encoded, 0); end != len(encoded) {
+			return nil, utils.InvalidArgumentError{Desc: "more than one object in $diag value"}
+		}
+		return append(b, encoded...), nil
+	}
+}
@@ -0,0 +1,150 @@
+package sbor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestToJSON(t *testing.T) {
+	data := []struct {
+		input    string
+		expected string
+		name     string
+	}{
+		{input: `{"a": [1, -8, 300_i16, 1.5], "b": nil, "c": true}`, expected: `{"a":[1,-8,300,1.5],"b":null,"c":true}`, name: "Plain"},
+		{input: `[h'ccff', ext(16, h'74657374'), ts("2022-03-06T15:20:00.000012345Z")]`, expected: `[{"$bin":"zP8="},{"$ext":[16,"dGVzdA=="]},{"$time":"2022-03-06T15:20:00.000012345Z"}]`, name: "Bin and ext"},
+		{input: `{1: "a", [2]: "b"}`, expected: `{"$map":[[1,"a"],[[2],"b"]]}`, name: "Non-string keys"},
+		{input: `{"$bin": 1}`, expected: `{"$map":[["$bin",1]]}`, name: "Tag key"},
+		{input: `[300, 1_u8, 18446744073709551615, -9007199254740993_i64]`, expected: `[{"$uint16":300},{"$uint8":1},{"$uint64":"18446744073709551615"},{"$int64":"-9007199254740993"}]`, name: "Integers"},
+		{input: `[0.1f32, 1.5f64, NaN, 2.0]`, expected: `[{"$float32":0.1},{"$float64":1.5},{"$float64":"NaN"},2.0]`, name: "Floats"},
+		{input: `[NaN(h'7fe31702'), ext(-1, h'000000000000003afff44180')]`, expected: `[{"$diag":"NaN(h'7fe31702')"},{"$ext":[-1,"AAAAAAAAADr/9EGA"]}]`, name: "Outside RFC 3339 and NaN payload"},
+		{input: `["a"_8, "\xff", []_16]`, expected: `[{"$diag":"\"a\"_8"},{"$diag":"\"\\xff\""},{"$diag":"[]_16"}]`, name: "Diag"},
+		{input: `"<a>" 1`, expected: "\"<a>\"\n1", name: "Stream"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			input, err := ParseDiag(test.input)
+			if err != nil {
+				t.Fatalf("ParseDiag Error: %v", err)
+			}
+
+			var b bytes.Buffer
+			if err = ToJSON(&b, input); err != nil {
+				t.Fatalf("ToJSON Error: %v", err)
+			}
+			if result := b.String(); result != test.expected+"\n" {
+				t.Errorf("Invalid result. Function returned %s. Expected %s.", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestToJSON_FromJSON(t *testing.T) {
+	fixtures := []string{
+		`{"a": [1, -8, 3.5f32, h'ccff', ext(16, h'74657374'), nil]}`,
+		`{1_u16: {"$diag": "x"}, 2.5: [true, false], h'00': {}_16, nil: ts("1969-12-31T23:59:59.5Z")}`,
+		`[-9007199254740992, 9007199254740992_u64, -1_i8, 0.1, 0.1f32, Infinityf32, -0.0]`,
+		`["é", "\u2028", "\x00", "\xc3"_16, "$", {"$time": 1, "x": 2}, {"$x": 1}]`,
+		`ext(-1, h'00') ext(-1, h'00000000000000006224d120') ext(5, h'0102')_32`,
+		`[ext(-1, h'000000000000003afff44180'), ext(-1, h'00000005fffffff1868b83ff'), NaN(h'7fe31702'), NaN(h'fff8000000000012'), NaNf32]`,
+		`{"k": [[[{}]]], "k": 1}`,
+		string(newTestEnvelopeDiag(t)),
+	}
+
+	for _, fixture := range fixtures {
+		input, err := ParseDiag(fixture)
+		if err != nil {
+			t.Fatalf("ParseDiag Error for %s: %v", fixture, err)
+		}
+
+		var j, result bytes.Buffer
+		if err = ToJSON(&j, input); err != nil {
+			t.Fatalf("ToJSON Error for %s: %v", fixture, err)
+		}
+		if err = FromJSON(&result, &j); err != nil {
+			t.Fatalf("FromJSON Error for %s: %v", fixture, err)
+		}
+
+		if !bytes.Equal(result.Bytes(), input) {
+			t.Errorf("Invalid round trip of %s. Function returned %v. Expected %v.", fixture, result.Bytes(), input)
+		}
+	}
+}
+
+// newTestEnvelopeDiag returns the diagnostic notation of the test envelope.
+func newTestEnvelopeDiag(t *testing.T) []byte {
+	var b bytes.Buffer
+	if err := Dump(&b, newTestEnvelope(t), DumpOptions{Compact: true}); err != nil {
+		t.Fatalf("Dump Error: %v", err)
+	}
+	return b.Bytes()
+}
+
+func TestFromJSON(t *testing.T) {
+	data := []struct {
+		input    string
+		expected string
+		name     string
+	}{
+		{input: `{"b": [1, -2, 300, 1.5, 0.1, 1e3], "a": null}`, expected: `{"b": [1, -2, 300_i16, 1.5, 0.1, 1000.0], "a": nil}`, name: "Plain"},
+		{input: `18446744073709551615 -9223372036854775808`, expected: `18446744073709551615 -9223372036854775808_i64`, name: "Big integers"},
+		{input: `{"$bin": "AQI=", "x": 1}`, expected: `{"$bin": "AQI=", "x": 1}`, name: "Tag with more keys"},
+		{input: `{"$uint32": "7"}`, expected: `7_u32`, name: "Integer in a string"},
+		{input: ``, expected: ``, name: "Empty"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var expected []byte
+			if test.expected != "" {
+				var err error
+				if expected, err = ParseDiag(test.expected); err != nil {
+					t.Fatalf("ParseDiag Error: %v", err)
+				}
+			}
+
+			var b bytes.Buffer
+			if err := FromJSON(&b, strings.NewReader(test.input)); err != nil {
+				t.Fatalf("FromJSON Error: %v", err)
+			}
+			if !bytes.Equal(b.Bytes(), expected) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", b.Bytes(), expected)
+			}
+		})
+	}
+}
+
+func TestFromJSON_Error(t *testing.T) {
+	data := []string{
+		`[1, 2`,
+		`{"a": }`,
+		`{"$bin": "!"}`,
+		`{"$ext": [300, ""]}`,
+		`{"$time": "now"}`,
+		`{"$map": [[1]]}`,
+		`{"$uint8": 256}`,
+		`{"$diag": "1 2"}`,
+		`{"$diag": "[1"}`,
+	}
+
+	for _, test := range data {
+		var b bytes.Buffer
+		if err := FromJSON(&b, strings.NewReader(test)); err == nil {
+			t.Errorf("Error was expected for %s.", test)
+		}
+	}
+}
+
+func TestToJSON_Error(t *testing.T) {
+	var b bytes.Buffer
+	err := ToJSON(&b, []byte{0xC0, 0x92, 0x01})
+
+	if syntax, ok := err.(SyntaxError); !ok || syntax.Offset != 3 {
+		t.Errorf("Invalid error. Function returned %v. Expected offset %d.", err, 3)
+	}
+	if b.String() != "null\n" {
+		t.Errorf("Invalid partial result %q.", b.String())
+	}
+}
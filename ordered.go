@@ -0,0 +1,80 @@
+package sbor
+
+import (
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
+)
+
+func init() {
+	// An OrderedMap inside a Go value is written in slice order
+	encode.RegisterBuiltin(OrderedMap(nil), func(e *encode.EncoderState, v reflect.Value) utils.MessagePackTypeEncoder {
+		m := make(types.Map, v.Len())
+		for i := range m {
+			pair := v.Index(i)
+			m[i].Key = e.TypeWrapper(pair.Field(0))
+			m[i].Value = e.TypeWrapper(pair.Field(1))
+		}
+		return m
+	})
+
+	// An OrderedMap inside a Go value is decoded in wire order
+	decode.RegisterBuiltin(OrderedMap(nil), func(d *decode.DecoderState, data []byte, offset int, v reflect.Value, depth int) (int, error) {
+		return d.Pairs(data, offset, v, depth)
+	})
+}
+
+// KV is an entry of an OrderedMap.
+type KV struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// OrderedMap is a MessagePack map that keeps the order of its entries and the duplicated keys,
+// for example to verify a signature computed over the encoded message.
+// Marshal writes its entries in slice order, also in canonical mode, and Unmarshal stores
+// the entries of a map in it in the order in which they appear in the message, with the keys
+// and the values decoded like in an empty interface. MessagePack nil sets it to nil.
+// Only the top-level map keeps its order: a map nested in a key or in a value is decoded into
+// a Go map, so to keep the order of the nested maps use a struct with OrderedMap fields.
+// Use Dedup or the duplicate key policy of the Decoder to remove the duplicated keys.
+type OrderedMap []KV
+
+// Dedup returns the entries of m without the duplicated keys, according to policy, in the
+// same order. DuplicateError returns a DuplicatedKeyError at the second entry with the same key.
+// It doesn't modify m. The keys are compared by their MessagePack encoding, so Dedup returns
+// an error if one of them can't be encoded.
+func (m OrderedMap) Dedup(policy DuplicatePolicy) (OrderedMap, error) {
+	if policy == DuplicateAllow {
+		return m, nil
+	}
+
+	// Index of the entry kept for every encoded key
+	keys := make([]string, len(m))
+	kept := make(map[string]int, len(m))
+	for i := range m {
+		key, err := Marshal(m[i].Key)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = string(key)
+
+		_, already := kept[keys[i]]
+		switch {
+		case already && policy == DuplicateError:
+			return nil, DuplicatedKeyError{Key: m[i].Key}
+		case !already || policy == DuplicateLast:
+			kept[keys[i]] = i
+		}
+	}
+
+	result := make(OrderedMap, 0, len(kept))
+	for i := range m {
+		if kept[keys[i]] == i {
+			result = append(result, m[i])
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package sbor
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedMap_Marshal(t *testing.T) {
+	type Test struct {
+		Header OrderedMap
+		Empty  OrderedMap
+	}
+
+	input := Test{Header: OrderedMap{{"b", 1}, {"a", []int{2}}, {"b", nil}, {uint8(7), true}}}
+
+	// The canonical mode doesn't sort the entries
+	var b bytes.Buffer
+	e := NewEncoder(&b)
+	e.SetCanonical(true)
+	if err := e.Encode(input); err != nil {
+		t.Fatalf("Encoder Error: %v", err)
+	}
+
+	expected := mustParseDiag(t, `{"Header": {"b": 1, "a": [2], "b": nil, 7: true}, "Empty": {}}`)
+	if !bytes.Equal(b.Bytes(), expected) {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", b.Bytes(), expected)
+	}
+}
+
+func TestOrderedMap_Unmarshal(t *testing.T) {
+	type Test struct {
+		Header OrderedMap
+		Empty  OrderedMap
+	}
+
+	result := Test{Empty: OrderedMap{{"x", 1}}}
+	if err := Unmarshal(mustParseDiag(t, `{"Header": {"z": 1, 2: [true], "z": nil}, "Empty": nil}`), &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+
+	expected := Test{Header: OrderedMap{{"z", uint64(1)}, {uint64(2), []interface{}{true}}, {"z", nil}}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Invalid result. Function returned %#v. Expected %#v.", result, expected)
+	}
+
+	var typeError UnmarshalTypeError
+	if err := Unmarshal(mustParseDiag(t, `[1, 2]`), &result.Header); !errors.As(err, &typeError) {
+		t.Errorf("UnmarshalTypeError was expected. Function returned %v.", err)
+	}
+
+	// The keys don't need to be hashable
+	if err := Unmarshal(mustParseDiag(t, `{[1]: 2}`), &result.Header); err != nil || !reflect.DeepEqual(result.Header, OrderedMap{{[]interface{}{uint64(1)}, uint64(2)}}) {
+		t.Errorf("Invalid result. Function returned %v, %v.", result.Header, err)
+	}
+
+	// The nested maps are Go maps, and their order is kept only by the OrderedMap fields
+	nested := mustParseDiag(t, `{"Header": {"a": {"z": 1, "y": 2}}, "Empty": {"z": 1, "y": 2}}`)
+	if err := Unmarshal(nested, &result); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+	expected = Test{
+		Header: OrderedMap{{"a", map[string]interface{}{"z": uint64(1), "y": uint64(2)}}},
+		Empty:  OrderedMap{{"z", uint64(1)}, {"y", uint64(2)}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Invalid result. Function returned %#v. Expected %#v.", result, expected)
+	}
+}
+
+func TestOrderedMap_MaxDepth(t *testing.T) {
+	config := NewConfig().WithMaxDepth(2)
+
+	// The maps count in the nesting depth like the other maps
+	var ordered []OrderedMap
+	if err := config.Unmarshal(mustParseDiag(t, `[{"a": 1}]`), &ordered); err != nil {
+		t.Errorf("Unmarshal Error: %v", err)
+	}
+
+	var syntax SyntaxError
+	var nested [][]OrderedMap
+	if err := config.Unmarshal(mustParseDiag(t, `[[{"a": 1}]]`), &nested); !errors.As(err, &syntax) {
+		t.Errorf("SyntaxError was expected. Function returned %v.", err)
+	}
+	if err := config.Unmarshal(mustParseDiag(t, `[{"a": [1]}]`), &ordered); !errors.As(err, &syntax) {
+		t.Errorf("SyntaxError was expected. Function returned %v.", err)
+	}
+}
+
+func TestOrderedMap_Dedup(t *testing.T) {
+	input := OrderedMap{{"a", 1}, {"b", 2}, {"a", 3}, {uint64(1), 4}, {int8(1), 5}}
+
+	data := []struct {
+		policy   DuplicatePolicy
+		expected OrderedMap
+		name     string
+	}{
+		{DuplicateAllow, input, "allow"},
+		{DuplicateFirst, OrderedMap{{"a", 1}, {"b", 2}, {uint64(1), 4}}, "first"},
+		{DuplicateLast, OrderedMap{{"b", 2}, {"a", 3}, {int8(1), 5}}, "last"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := input.Dedup(test.policy)
+			if err != nil {
+				t.Fatalf("Dedup Error: %v", err)
+			}
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result, test.expected)
+			}
+		})
+	}
+
+	var duplicated DuplicatedKeyError
+	if _, err := input.Dedup(DuplicateError); !errors.As(err, &duplicated) || duplicated.Key != "a" {
+		t.Errorf("DuplicatedKeyError was expected for the key a. Function returned %v.", err)
+	}
+	if _, err := (OrderedMap{{complex(1, 1), 1}}).Dedup(DuplicateFirst); err == nil {
+		t.Error("Error was expected with a key that can't be encoded.")
+	}
+}
@@ -0,0 +1,170 @@
+package sbor
+
+import (
+	"errors"
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+)
+
+// Set returns a copy of the MessagePack data where the object found at the given path
+// is replaced by the MessagePack encoding of value, without decoding the whole message.
+// See Get for the details about the path, and Marshal for the encoding of value.
+//
+// If the last path element is a key that doesn't exist in its map, the key-value pair
+// is added at the end of the map. If it's an index equal to the length of its array,
+// value is added at the end of the array. In both cases the header of the parent is
+// rewritten with the smallest format for the new length, so for example a fixmap with
+// 15 pairs becomes a map16 with 16 pairs.
+//
+// An empty path replaces the whole message. Data isn't modified.
+//
+//   // Add a trace_id to the envelope
+//   message, err = sbor.Set(message, traceID, "header", "trace_id")
+func Set(data []byte, value interface{}, path ...interface{}) ([]byte, error) {
+	encoded, err := Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(path) == 0 {
+		return encoded, nil
+	}
+
+	parent, parentEnd, err := find(data, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := decode.ReadHeader(data, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+	switch h.Kind {
+	case decode.Map:
+		_, valueStart, err := findKey(data, parent+h.Size, h.Length, last)
+		if errors.As(err, &utils.NotFoundError{}) {
+			// New key-value pair
+			key, err := Marshal(last)
+			if err != nil {
+				return nil, err
+			}
+			return splice(data, parent, h, h.Length+1, parentEnd, parentEnd, append(key, encoded...))
+		} else if err != nil {
+			return nil, err
+		}
+
+		valueEnd, err := decode.Skip(data, valueStart)
+		if err != nil {
+			return nil, err
+		}
+		return replace(data, valueStart, valueEnd, encoded), nil
+
+	case decode.Array:
+		if i, ok := pathInteger(last); ok && !i.negative && i.value == uint64(h.Length) {
+			// New element
+			return splice(data, parent, h, h.Length+1, parentEnd, parentEnd, encoded)
+		}
+
+		start, err := findIndex(data, parent+h.Size, h.Length, last)
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := decode.Skip(data, start)
+		if err != nil {
+			return nil, err
+		}
+		return replace(data, start, end, encoded), nil
+
+	default:
+		return nil, utils.NotFoundError{Key: last}
+	}
+}
+
+// Delete returns a copy of the MessagePack data where the object found at the given path
+// is removed from its parent, without decoding the whole message.
+// See Get for the details about the path.
+//
+// If the last path element is a map key the whole key-value pair is removed, if it's an
+// array index the following elements are shifted. The header of the parent is rewritten
+// with the smallest format for the new length, so for example a map16 with 16 pairs
+// becomes a fixmap with 15 pairs.
+//
+// The path can't be empty. Data isn't modified.
+func Delete(data []byte, path ...interface{}) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, utils.InvalidArgumentError{Desc: "empty path"}
+	}
+
+	parent, _, err := find(data, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := decode.ReadHeader(data, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	last := path[len(path)-1]
+	var start, end int
+
+	switch h.Kind {
+	case decode.Map:
+		var valueStart int
+		if start, valueStart, err = findKey(data, parent+h.Size, h.Length, last); err != nil {
+			return nil, err
+		}
+		if end, err = decode.Skip(data, valueStart); err != nil {
+			return nil, err
+		}
+
+	case decode.Array:
+		if start, err = findIndex(data, parent+h.Size, h.Length, last); err != nil {
+			return nil, err
+		}
+		if end, err = decode.Skip(data, start); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, utils.NotFoundError{Key: last}
+	}
+
+	return splice(data, parent, h, h.Length-1, start, end, nil)
+}
+
+// replace returns a copy of data where the bytes in [from, to) are replaced by insert.
+func replace(data []byte, from int, to int, insert []byte) []byte {
+	result := make([]byte, 0, len(data)-(to-from)+len(insert))
+	result = append(result, data[:from]...)
+	result = append(result, insert...)
+	return append(result, data[to:]...)
+}
+
+// splice returns a copy of data where the map or the array with header h that starts
+// at data[start] has a header for length elements, and where the bytes in [from, to)
+// are replaced by insert.
+func splice(data []byte, start int, h decode.Header, length int, from int, to int, insert []byte) ([]byte, error) {
+	var header []byte
+	var err error
+
+	if h.Kind == decode.Map {
+		header, err = types.MapHeader(length)
+	} else {
+		header, err = types.ArrayHeader(length)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, len(data)-h.Size+len(header)-(to-from)+len(insert))
+	result = append(result, data[:start]...)
+	result = append(result, header...)
+	result = append(result, data[start+h.Size:from]...)
+	result = append(result, insert...)
+	return append(result, data[to:]...), nil
+}
@@ -0,0 +1,146 @@
+package sbor
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// newTestMap returns a map Value with length pairs, from "k0": 0 to "kN": N.
+func newTestMap(length int) Value {
+	pairs := make([]Pair, length)
+	for i := range pairs {
+		pairs[i] = Pair{Key: NewString("k" + strconv.Itoa(i)), Value: NewInt(int64(i))}
+	}
+	return NewMap(pairs...)
+}
+
+func TestSet(t *testing.T) {
+	data := newTestEnvelope(t)
+
+	result, err := Set(data, "other tenant", "header", "tenant")
+	if err != nil {
+		t.Fatalf("Set Error: %v", err)
+	}
+	if s, err := GetString(result, "header", "tenant"); err != nil || s != "other tenant" {
+		t.Errorf("Invalid replaced value %s. Error: %v", s, err)
+	}
+	if s, err := GetString(result, "payload", 1, 1, "sku"); err != nil || s != "B-2" {
+		t.Errorf("Invalid following value %s. Error: %v", s, err)
+	}
+
+	result, err = Set(result, []byte{0x01, 0x02}, "header", "trace_id")
+	if err != nil {
+		t.Fatalf("Set Error: %v", err)
+	}
+	if b, err := GetBytes(result, "header", "trace_id"); err != nil || !bytes.Equal(b, []byte{0x01, 0x02}) {
+		t.Errorf("Invalid added value %v. Error: %v", b, err)
+	}
+
+	result, err = Set(result, testItem{SKU: "C-3"}, "payload", 1, 2)
+	if err != nil {
+		t.Fatalf("Set Error: %v", err)
+	}
+	if s, err := GetString(result, "payload", 1, 2, "sku"); err != nil || s != "C-3" {
+		t.Errorf("Invalid appended value %s. Error: %v", s, err)
+	}
+
+	result, err = Set(result, true)
+	if err != nil || !bytes.Equal(result, []byte{0xC3}) {
+		t.Errorf("Invalid whole replacement %v. Error: %v", result, err)
+	}
+
+	if !bytes.Equal(data, newTestEnvelope(t)) {
+		t.Error("Input data has been modified.")
+	}
+}
+
+func TestSet_Delete_SizeClass(t *testing.T) {
+	data := []struct {
+		length int
+		name   string
+	}{
+		{length: 15, name: "fixmap to map16"},
+		{length: 1<<16 - 1, name: "map16 to map32"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			before, _ := newTestMap(test.length).Marshal()
+			after, _ := newTestMap(test.length + 1).Marshal()
+			key := "k" + strconv.Itoa(test.length)
+
+			result, err := Set(before, test.length, key)
+			if err != nil {
+				t.Fatalf("Set Error: %v", err)
+			}
+			if !bytes.Equal(result, after) {
+				t.Errorf("Set output different than expected. Header %v. Expected %v.", result[:5], after[:5])
+			}
+
+			result, err = Delete(after, key)
+			if err != nil {
+				t.Fatalf("Delete Error: %v", err)
+			}
+			if !bytes.Equal(result, before) {
+				t.Errorf("Delete output different than expected. Header %v. Expected %v.", result[:5], before[:5])
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	array, _ := NewArray(NewString("a"), NewString("b"), NewString("c")).Marshal()
+	expected, _ := NewArray(NewString("a"), NewString("c")).Marshal()
+
+	result, err := Delete(array, 1)
+	if err != nil {
+		t.Fatalf("Delete Error: %v", err)
+	}
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Delete output different than expected. Returned %v. Expected %v.", result, expected)
+	}
+
+	data := newTestEnvelope(t)
+	if result, err = Delete(data, "payload", 1, 0); err != nil {
+		t.Fatalf("Delete Error: %v", err)
+	}
+	if s, err := GetString(result, "payload", 1, 0, "sku"); err != nil || s != "B-2" {
+		t.Errorf("Invalid shifted value %s. Error: %v", s, err)
+	}
+	if _, err = Get(result, "payload", 1, 1); err == nil {
+		t.Error("Deleted element found.")
+	}
+}
+
+func TestSet_Delete_Error(t *testing.T) {
+	data := newTestEnvelope(t)
+	var notFound NotFoundError
+
+	if _, err := Set(data, 1, "missing", "key"); !errors.As(err, &notFound) {
+		t.Errorf("NotFoundError was expected, returned %v.", err)
+	}
+	if _, err := Set(data, 1, "payload", 1, 3); !errors.As(err, &notFound) {
+		t.Errorf("NotFoundError was expected, returned %v.", err)
+	}
+	if _, err := Set(data, 1, "header", "tenant", "x"); !errors.As(err, &notFound) {
+		t.Errorf("NotFoundError was expected, returned %v.", err)
+	}
+	if _, err := Set(data, 1, "header", 1.5); err == nil {
+		t.Error("Invalid path element error was expected.")
+	}
+	if _, err := Set(data, complex64(1), "header"); err == nil {
+		t.Error("Invalid value error was expected.")
+	}
+
+	if _, err := Delete(data); err == nil {
+		t.Error("Empty path error was expected.")
+	}
+	if _, err := Delete(data, "header", "missing"); !errors.As(err, &notFound) {
+		t.Errorf("NotFoundError was expected, returned %v.", err)
+	}
+	if _, err := Delete(data, "payload", 1, 2); !errors.As(err, &notFound) {
+		t.Errorf("NotFoundError was expected, returned %v.", err)
+	}
+}
@@ -0,0 +1,283 @@
+package sbor
+
+import (
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"math"
+	"reflect"
+)
+
+// Get returns the object found at the given path inside the MessagePack data,
+// without decoding the whole message: the unneeded objects are skipped using
+// the lengths written in their headers.
+//
+// Each path element selects a child of the current object:
+// a string selects the value of a map with that string key, an integer selects
+// the value of a map with that integer key (like the ones written with the
+// "customkey" option) or the element of an array with that index.
+// If a map contains a duplicated key, the first value is used.
+//
+// The result references data, so data must not be modified while it's used.
+// If an element doesn't exist a NotFoundError is returned, and if data is
+// invalid a SyntaxError is returned.
+//
+//   // Equivalent to message["payload"]["items"][0]["sku"]
+//   sku, err := sbor.Get(message, "payload", "items", 0, "sku")
+func Get(data []byte, path ...interface{}) (RawMessage, error) {
+	start, end, err := find(data, path)
+	if err != nil {
+		return nil, err
+	}
+	return RawMessage(data[start:end:end]), nil
+}
+
+// find returns the start and the end offsets of the object at path inside data.
+func find(data []byte, path []interface{}) (int, int, error) {
+	var offset int
+
+	for _, element := range path {
+		h, err := decode.ReadHeader(data, offset)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		switch h.Kind {
+		case decode.Map:
+			_, offset, err = findKey(data, offset+h.Size, h.Length, element)
+		case decode.Array:
+			offset, err = findIndex(data, offset+h.Size, h.Length, element)
+		default:
+			err = utils.NotFoundError{Key: element}
+		}
+
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	end, err := decode.Skip(data, offset)
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, end, nil
+}
+
+// findKey returns the offsets of key and of its associated value, in the map
+// with length pairs whose first key starts at data[offset].
+func findKey(data []byte, offset int, length int, key interface{}) (int, int, error) {
+	match, err := keyMatcher(key)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i := 0; i < length; i++ {
+		h, err := decode.ReadHeader(data, offset)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		valueOffset, err := decode.Skip(data, offset)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if match(data, offset, h) {
+			return offset, valueOffset, nil
+		}
+
+		if offset, err = decode.Skip(data, valueOffset); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return 0, 0, utils.NotFoundError{Key: key}
+}
+
+// findIndex returns the offset of the element with the given index, in the
+// array with length elements whose first element starts at data[offset].
+func findIndex(data []byte, offset int, length int, index interface{}) (int, error) {
+	i, ok := pathInteger(index)
+	if !ok || i.negative || i.value >= uint64(length) {
+		return 0, utils.NotFoundError{Key: index}
+	}
+
+	var err error
+	for n := uint64(0); n < i.value; n++ {
+		if offset, err = decode.Skip(data, offset); err != nil {
+			return 0, err
+		}
+	}
+
+	return offset, nil
+}
+
+// integer is an integer path element, stored as its absolute value and sign.
+type integer struct {
+	value    uint64
+	negative bool
+}
+
+// pathInteger returns the integer value of a path element, if it's an integer.
+func pathInteger(element interface{}) (integer, bool) {
+	v := reflect.ValueOf(element)
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i := v.Int()
+		if i < 0 {
+			return integer{value: uint64(-(i + 1)) + 1, negative: true}, true
+		}
+		return integer{value: uint64(i)}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return integer{value: v.Uint()}, true
+	default:
+		return integer{}, false
+	}
+}
+
+// keyMatcher returns a function that reports whether the object with header h
+// that starts at data[offset] is equal to the path element key.
+func keyMatcher(key interface{}) (func(data []byte, offset int, h decode.Header) bool, error) {
+	if v := reflect.ValueOf(key); v.Kind() == reflect.String {
+		s := v.String()
+		return func(data []byte, offset int, h decode.Header) bool {
+			start := offset + h.Size
+			return h.Kind == decode.String && h.Length == len(s) && string(data[start:start+h.Length]) == s
+		}, nil
+	}
+
+	if i, ok := pathInteger(key); ok {
+		return func(data []byte, offset int, h decode.Header) bool {
+			switch h.Kind {
+			case decode.Uint:
+				return !i.negative && decode.ReadUint(data, offset, h) == i.value
+			case decode.Int:
+				value := decode.ReadInt(data, offset, h)
+				if value < 0 {
+					return i.negative && uint64(-(value+1))+1 == i.value
+				}
+				return !i.negative && uint64(value) == i.value
+			default:
+				return false
+			}
+		}, nil
+	}
+
+	return nil, utils.InvalidArgumentError{Desc: "path elements must be strings or integers"}
+}
+
+// GetString returns the string found at the given path inside the MessagePack data.
+// See Get for the details about the path.
+func GetString(data []byte, path ...interface{}) (string, error) {
+	offset, h, err := getScalar(data, path, decode.String)
+	if err != nil {
+		return "", err
+	}
+
+	start := offset + h.Size
+	return string(data[start : start+h.Length]), nil
+}
+
+// GetBytes returns the content of the binary found at the given path inside the MessagePack data.
+// The result references data. See Get for the details about the path.
+func GetBytes(data []byte, path ...interface{}) ([]byte, error) {
+	offset, h, err := getScalar(data, path, decode.Binary)
+	if err != nil {
+		return nil, err
+	}
+
+	start := offset + h.Size
+	end := start + h.Length
+	return data[start:end:end], nil
+}
+
+// GetInt returns the integer found at the given path inside the MessagePack data.
+// It returns an error if the integer doesn't fit in an int64.
+// See Get for the details about the path.
+func GetInt(data []byte, path ...interface{}) (int64, error) {
+	offset, h, err := getScalar(data, path, decode.Int, decode.Uint)
+	if err != nil {
+		return 0, err
+	}
+
+	if h.Kind == decode.Int {
+		return decode.ReadInt(data, offset, h), nil
+	}
+
+	u := decode.ReadUint(data, offset, h)
+	if u > math.MaxInt64 {
+		return 0, utils.InvalidTypeError{Type: "uint64 value overflows int64"}
+	}
+	return int64(u), nil
+}
+
+// GetUint returns the unsigned integer found at the given path inside the MessagePack data.
+// It returns an error if the integer is negative.
+// See Get for the details about the path.
+func GetUint(data []byte, path ...interface{}) (uint64, error) {
+	offset, h, err := getScalar(data, path, decode.Int, decode.Uint)
+	if err != nil {
+		return 0, err
+	}
+
+	if h.Kind == decode.Uint {
+		return decode.ReadUint(data, offset, h), nil
+	}
+
+	i := decode.ReadInt(data, offset, h)
+	if i < 0 {
+		return 0, utils.InvalidTypeError{Type: "negative value for uint64"}
+	}
+	return uint64(i), nil
+}
+
+// GetFloat returns the number found at the given path inside the MessagePack data, as a float64.
+// Integers are converted to float64. See Get for the details about the path.
+func GetFloat(data []byte, path ...interface{}) (float64, error) {
+	offset, h, err := getScalar(data, path, decode.Float, decode.Int, decode.Uint)
+	if err != nil {
+		return 0, err
+	}
+
+	switch h.Kind {
+	case decode.Int:
+		return float64(decode.ReadInt(data, offset, h)), nil
+	case decode.Uint:
+		return float64(decode.ReadUint(data, offset, h)), nil
+	default:
+		return decode.ReadFloat(data, offset, h), nil
+	}
+}
+
+// GetBool returns the boolean found at the given path inside the MessagePack data.
+// See Get for the details about the path.
+func GetBool(data []byte, path ...interface{}) (bool, error) {
+	_, h, err := getScalar(data, path, decode.Boolean)
+	if err != nil {
+		return false, err
+	}
+	return h.Code == types.True, nil
+}
+
+// getScalar returns the offset and the header of the object at path,
+// checking that its kind is one of the expected kinds.
+func getScalar(data []byte, path []interface{}, kinds ...decode.Kind) (int, decode.Header, error) {
+	offset, _, err := find(data, path)
+	if err != nil {
+		return 0, decode.Header{}, err
+	}
+
+	h, err := decode.ReadHeader(data, offset)
+	if err != nil {
+		return 0, decode.Header{}, err
+	}
+
+	for _, kind := range kinds {
+		if h.Kind == kind {
+			return offset, h, nil
+		}
+	}
+
+	return 0, decode.Header{}, utils.InvalidTypeError{Type: h.Kind.String() + " instead of " + kinds[0].String()}
+}
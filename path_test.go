@@ -0,0 +1,168 @@
+package sbor
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type testItem struct {
+	SKU   string  `sbor:"sku"`
+	Price float64 `sbor:"price"`
+}
+
+type testEnvelope struct {
+	Header struct {
+		Tenant string `sbor:"tenant"`
+		Retry  bool   `sbor:"retry"`
+	} `sbor:"header"`
+	Payload struct {
+		Keys  map[string]int `sbor:",setcustomkeys"`
+		Items []testItem     `sbor:"items,customkey"`
+		Count int            `sbor:"count,customkey"`
+		Raw   []byte         `sbor:"raw"`
+		Big   uint64         `sbor:"big"`
+	} `sbor:"payload"`
+}
+
+func newTestEnvelope(t *testing.T) []byte {
+	var e testEnvelope
+	e.Header.Tenant = "acme"
+	e.Header.Retry = true
+	e.Payload.Keys = map[string]int{"items": 1, "count": -2}
+	e.Payload.Items = []testItem{{SKU: "A-1", Price: 9.5}, {SKU: "B-2", Price: 1.37}}
+	e.Payload.Count = -40000
+	e.Payload.Raw = []byte{0xCC, 0xFF}
+	e.Payload.Big = 1 << 63
+
+	data, err := Marshal(e)
+	if err != nil {
+		t.Fatalf("Marshal Error: %v", err)
+	}
+	return data
+}
+
+func TestGet(t *testing.T) {
+	data := newTestEnvelope(t)
+
+	raw, err := Get(data, "payload", 1, 1)
+	if err != nil {
+		t.Fatalf("Get Error: %v", err)
+	}
+
+	expected, _ := Marshal(testItem{SKU: "B-2", Price: 1.37})
+	if !bytes.Equal(raw, expected) {
+		t.Errorf("Get output different than expected. Returned %v. Expected %v.", raw, expected)
+	}
+
+	if raw, err = Get(data); err != nil || !bytes.Equal(raw, data) {
+		t.Errorf("Empty path must return the whole message. Error: %v", err)
+	}
+}
+
+func TestGet_Typed(t *testing.T) {
+	data := newTestEnvelope(t)
+
+	if s, err := GetString(data, "header", "tenant"); err != nil || s != "acme" {
+		t.Errorf("Invalid string %s. Error: %v", s, err)
+	}
+
+	if s, err := GetString(data, "payload", uint8(1), 0, "sku"); err != nil || s != "A-1" {
+		t.Errorf("Invalid string %s. Error: %v", s, err)
+	}
+
+	if b, err := GetBool(data, "header", "retry"); err != nil || !b {
+		t.Errorf("Invalid boolean %v. Error: %v", b, err)
+	}
+
+	if i, err := GetInt(data, "payload", -2); err != nil || i != -40000 {
+		t.Errorf("Invalid integer %d. Error: %v", i, err)
+	}
+
+	if u, err := GetUint(data, "payload", "big"); err != nil || u != 1<<63 {
+		t.Errorf("Invalid unsigned integer %d. Error: %v", u, err)
+	}
+
+	if f, err := GetFloat(data, "payload", 1, 0, "price"); err != nil || f != 9.5 {
+		t.Errorf("Invalid float %v. Error: %v", f, err)
+	}
+
+	if f, err := GetFloat(data, "payload", -2); err != nil || f != -40000 {
+		t.Errorf("Invalid float from integer %v. Error: %v", f, err)
+	}
+
+	if b, err := GetBytes(data, "payload", "raw"); err != nil || !bytes.Equal(b, []byte{0xCC, 0xFF}) {
+		t.Errorf("Invalid bytes %v. Error: %v", b, err)
+	}
+}
+
+func TestGet_Error(t *testing.T) {
+	data := newTestEnvelope(t)
+
+	var notFound NotFoundError
+	paths := [][]interface{}{
+		{"missing"},
+		{"payload", 1, 2},
+		{"payload", 1, -1},
+		{"header", "tenant", "x"},
+		{"payload", 2},
+	}
+	for _, path := range paths {
+		if _, err := Get(data, path...); !errors.As(err, &notFound) {
+			t.Errorf("NotFoundError was expected for %v, returned %v.", path, err)
+		}
+	}
+
+	if _, err := Get(data, 1.5); err == nil {
+		t.Error("Invalid path element error was expected.")
+	}
+
+	if _, err := GetInt(data, "header", "tenant"); err == nil {
+		t.Error("Invalid type error was expected.")
+	}
+
+	if _, err := GetInt(data, "payload", "big"); err == nil {
+		t.Error("Overflow error was expected.")
+	}
+
+	if _, err := GetUint(data, "payload", -2); err == nil {
+		t.Error("Negative value error was expected.")
+	}
+
+	var syntax SyntaxError
+	if _, err := Get(data[:len(data)-1], "payload", "big"); !errors.As(err, &syntax) {
+		t.Errorf("SyntaxError was expected, returned %v.", err)
+	}
+}
+
+func TestRawMessage_Marshal(t *testing.T) {
+	exampleStruct := struct {
+		Raw   RawMessage `sbor:"r"`
+		Empty RawMessage `sbor:"e"`
+	}{
+		Raw: RawMessage{0x92, 0xC3, 0xC0},
+	}
+
+	expected := []byte{0x82, 0xA1, 0x72, 0x92, 0xC3, 0xC0, 0xA1, 0x65, 0xC0}
+
+	result, err := Marshal(exampleStruct)
+	if err != nil {
+		t.Errorf("Marshal Error: %v", err)
+	}
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Marshal output different than expected. Returned %v. Expected %v.", result, expected)
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	var e testEnvelope
+	e.Header.Tenant = "acme"
+	e.Payload.Keys = map[string]int{"items": 1, "count": -2}
+	e.Payload.Items = make([]testItem, 100)
+	data, _ := Marshal(e)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = GetString(data, "payload", 1, 99, "sku")
+	}
+}
@@ -0,0 +1,41 @@
+package sbor
+
+import (
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
+)
+
+func init() {
+	// A RawMessage inside a Go value is written as it is
+	encode.RegisterBuiltin(RawMessage(nil), func(_ *encode.EncoderState, v reflect.Value) utils.MessagePackTypeEncoder {
+		if v.Len() == 0 {
+			return types.Nil{}
+		}
+		if err := Valid(v.Bytes()); err != nil {
+			return utils.ErrorMessagePackType("invalid RawMessage (" + err.Error() + ")")
+		}
+		return types.Encoded(v.Bytes())
+	})
+
+	// A RawMessage inside a Go value is decoded as the encoded object,
+	// copied unless the decoder references the input data
+	decode.RegisterBuiltin(RawMessage(nil), func(d *decode.DecoderState, data []byte, offset int, v reflect.Value, _ int) (int, error) {
+		end, err := decode.Skip(data, offset)
+		if err == nil {
+			v.SetBytes(d.Bytes(data[offset:end]))
+		}
+		return end, err
+	})
+}
+
+// RawMessage is a raw encoded MessagePack object.
+// It can be used to delay the decoding of a part of a message, or to
+// insert an already encoded object when encoding, because Marshal writes
+// it as it is, after checking with Valid that it contains exactly one object,
+// and Unmarshal stores in it a copy of the encoded object, or the encoded object
+// itself with UnmarshalNoCopy.
+// An empty RawMessage is encoded as nil.
+type RawMessage []byte
@@ -0,0 +1,239 @@
+package sbor
+
+import (
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"io"
+	"reflect"
+)
+
+// minRead is the minimum free space in the buffer of a Decoder before a read.
+const minRead = 512
+
+// CustomDecoder specifies a function to decode an external type into the associated type,
+// when the MessagePackCustom interface is not implemented by a pointer to the type.
+//
+// Decoder receives the payload of the external type and a pointer to the value to fill,
+// and returns an error if the payload is invalid. The payload is a copy, unless the
+// decoder references the input data, and then it must not be modified.
+//
+// For a large payload, Decoder can be replaced with Reader, that reads the payload of
+// the given size from r, for example to decompress it while reading. The part of the
+// payload that is not read is skipped.
+type CustomDecoder struct {
+	Decoder func(data []byte, v interface{}) error
+	Reader  func(r io.Reader, size int, v interface{}) error
+}
+
+// DuplicatePolicy chooses how the keys repeated in a map are handled.
+type DuplicatePolicy = decode.DuplicatePolicy
+
+// The duplicate key policies.
+const (
+	// DuplicateAllow keeps all the entries. A Go map or a struct field stores the last value.
+	DuplicateAllow = decode.DuplicateAllow
+	// DuplicateError returns an error at the second entry with the same key.
+	DuplicateError = decode.DuplicateError
+	// DuplicateFirst keeps only the first entry with the same key.
+	DuplicateFirst = decode.DuplicateFirst
+	// DuplicateLast keeps only the last entry with the same key.
+	DuplicateLast = decode.DuplicateLast
+)
+
+// A Decoder reads and decodes MessagePack values from an input stream.
+type Decoder struct {
+	r     io.Reader
+	buf   []byte // Data read but not decoded yet
+	err   error  // Error returned by the last read
+	state *decode.DecoderState
+}
+
+// NewDecoder returns a new decoder that reads from r.
+// The decoder reads data in chunks, so it may read from r beyond the requested objects.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:     r,
+		state: decode.NewDecoderState(),
+	}
+}
+
+// SetExternalType associate a type with an external type code, for this Decoder.
+// An external type with that code is decoded into the values of the type, and into
+// the pointers to it, allocating the value if needed. The other objects are decoded as usual,
+// so a time.Time still accepts the timestamp external type, and MessagePack nil sets
+// a pointer to nil.
+//
+// ID is the correspondent MessagePack External type, and must be a number between 0 and 127.
+//
+// Value is an instance of the type, or a pointer to it. If the pointer implements
+// MessagePackCustom interface, the corresponding method UnmarshalMsgpack will be used
+// for decoding, else you have to provide a CustomDecoder function.
+func (d *Decoder) SetExternalType(id int8, value interface{}, c ...CustomDecoder) error {
+	return setExternalDecoder(d.state, id, value, c...)
+}
+
+// setExternalDecoder associate a type with an external type code in state.
+func setExternalDecoder(state *decode.DecoderState, id int8, value interface{}, c ...CustomDecoder) error {
+	t := reflect.TypeOf(value)
+	if t != nil && t.Kind() != reflect.Ptr {
+		t = reflect.PtrTo(t)
+	}
+	if t != nil && t.Implements(reflect.TypeOf((*MessagePackCustom)(nil)).Elem()) {
+		return state.SetExternalTypeHandler(value, decode.ExtUserHandler{
+			Type: id,
+			Decoder: func(data []byte, v interface{}) error {
+				return v.(MessagePackCustom).UnmarshalMsgpack(data)
+			},
+		})
+	}
+
+	if len(c) != 1 {
+		return utils.InvalidArgumentError{Desc: "CustomDecoder expected"}
+	}
+
+	return state.SetExternalTypeHandler(value, decode.ExtUserHandler{
+		Type:    id,
+		Decoder: c[0].Decoder,
+		Reader:  c[0].Reader,
+	})
+}
+
+// SetTagName sets the keys of the struct field's tag read by this Decoder, in order of precedence.
+// See the documentation of Encoder.SetTagName for details.
+func (d *Decoder) SetTagName(names ...string) {
+	d.state.TagNames = tagNames(names)
+}
+
+// SetCompatMode sets whether this Decoder reads the strings as raw bytes, like the old MessagePack
+// specification that has a single raw type for both text and binary data. In compatibility mode
+// a string can be stored in []byte and in a byte array too, and in an empty interface it is
+// stored as []byte, except for the map keys that remain strings.
+func (d *Decoder) SetCompatMode(compat bool) {
+	d.state.CompatMode = compat
+}
+
+// SetNoCopy sets whether the byte slices and the RawMessage values decoded by this Decoder
+// reference its internal buffer instead of a copy of it, like UnmarshalNoCopy.
+// The Decoder never overwrites the data it already returned, so the slices remain valid
+// after the next calls to Decode, but they keep in memory the chunk of input they belong to.
+func (d *Decoder) SetNoCopy(noCopy bool) {
+	d.state.NoCopy = noCopy
+}
+
+// SetInternKeys sets whether this Decoder reuses the same string for equal string keys,
+// when the target is a Go map or an empty interface. It saves memory when decoding
+// many maps with the same keys, also in different calls to Decode.
+// At most 4096 different keys are remembered.
+func (d *Decoder) SetInternKeys(intern bool) {
+	d.state.InternKeys = intern
+}
+
+// SetTimePolicy sets the unit of the integers decoded into time.Time values by this Decoder:
+// milliseconds for TimeUnixMilli, nanoseconds for TimeUnixNano and seconds for the other policies.
+// A struct field can override it with the "time=" option, like when encoding. The other formats
+// of the time policies are always accepted.
+func (d *Decoder) SetTimePolicy(p TimePolicy) {
+	d.state.Time = p
+}
+
+// SetZonedTimeType sets the external type code of the times with their zone read by this Decoder,
+// written with the TimeZoned policy, between 0 and 127. The default is DefaultZonedTimeType.
+// The location is loaded with time.LoadLocation, and if it isn't available on this machine,
+// or it has a different offset, a fixed zone with the same name and offset is used.
+func (d *Decoder) SetZonedTimeType(id int8) error {
+	if id < 0 {
+		return utils.OutOfBoundError{Key: int(id)}
+	}
+	d.state.ZonedTimeType = id
+	return nil
+}
+
+// SetDuplicateKeys sets how this Decoder handles the keys that appear more than once in a map,
+// that MessagePack allows, when it's stored in a Go map, in a struct, in an OrderedMap or in
+// an empty interface, also when the map is nested in the value of another one.
+// The default, DuplicateAllow, stores the last value in the Go maps and in the struct fields,
+// and keeps all the entries in an OrderedMap. With DuplicateError a repeated key returns a
+// DuplicatedKeyError with the key and its offset, to reject the messages that could be read
+// differently by other readers.
+//
+// The keys of a Go map are the same if they are equal as Go values, so 1 and uint 16 1 are the
+// same key of a map[int]string, and the keys of a struct are the same if they match the same field.
+// The keys of an OrderedMap are the same if their encoding with the default formats is the same.
+func (d *Decoder) SetDuplicateKeys(p DuplicatePolicy) {
+	d.state.DuplicateKeys = p
+}
+
+// DisallowUnknownFields causes this Decoder to return an UnknownFieldError when a map is
+// stored in a struct and one of its keys doesn't match any field, by name or by custom key,
+// instead of ignoring it. A struct with the "structarray" option returns an UnmarshalTypeError
+// if the length of the array is different from the number of its fields.
+func (d *Decoder) DisallowUnknownFields() {
+	d.state.DisallowUnknownFields = true
+}
+
+// SetMaxDepth sets the maximum nesting depth of the arrays and maps decoded by this Decoder,
+// to reject the messages that would need too much work. A depth of 1 allows only flat arrays
+// and maps. Zero, the default, or a depth above 10000 means the limit of the package, 10000.
+func (d *Decoder) SetMaxDepth(depth int) {
+	d.state.MaxDepth = depth
+}
+
+// SetMaxLength sets the maximum number of elements of the arrays and maps, and the maximum
+// number of bytes of the other objects, decoded by this Decoder, to reject the messages that
+// would allocate too much memory. Zero, the default, means no limit.
+func (d *Decoder) SetMaxLength(length int) {
+	d.state.MaxLength = length
+}
+
+// Decode reads the next MessagePack object from its input and stores it in the value pointed to by v.
+// See the documentation for Unmarshal for details about the conversion of MessagePack into a Go value.
+//
+// At the end of the input Decode returns io.EOF, and if the input ends in the middle of
+// an object it returns io.ErrUnexpectedEOF.
+func (d *Decoder) Decode(v interface{}) error {
+	end, err := d.readObject()
+	if err != nil {
+		return err
+	}
+
+	data := d.buf[:end]
+	d.buf = d.buf[end:]
+	return unmarshal(d.state, data, v)
+}
+
+// readObject reads from the input until the buffer starts with a complete object,
+// and returns its length.
+func (d *Decoder) readObject() (int, error) {
+	for {
+		if len(d.buf) > 0 {
+			end, err := decode.Skip(d.buf, 0)
+			if err == nil {
+				return end, nil
+			}
+			if !decode.IsTruncated(err) {
+				return 0, err
+			}
+		}
+
+		if d.err != nil {
+			if d.err == io.EOF && len(d.buf) > 0 {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, d.err
+		}
+		d.fill()
+	}
+}
+
+// fill reads the next chunk of the input, growing the buffer if needed.
+func (d *Decoder) fill() {
+	if cap(d.buf)-len(d.buf) < minRead {
+		buf := make([]byte, len(d.buf), 2*len(d.buf)+minRead)
+		copy(buf, d.buf)
+		d.buf = buf
+	}
+
+	n, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+	d.buf = d.buf[:len(d.buf)+n]
+	d.err = err
+}
@@ -0,0 +1,367 @@
+package sbor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder_Decode(t *testing.T) {
+	input := append(mustParseDiag(t, `{"a": [1, 2]} "text" nil`), bytes.Repeat([]byte{0x01}, 1000)...)
+	d := NewDecoder(iotest.OneByteReader(bytes.NewReader(input)))
+
+	var m map[string][]int
+	if err := d.Decode(&m); err != nil || len(m["a"]) != 2 {
+		t.Errorf("Invalid result. Function returned %v, %v.", m, err)
+	}
+
+	var s string
+	if err := d.Decode(&s); err != nil || s != "text" {
+		t.Errorf("Invalid result. Function returned %v, %v.", s, err)
+	}
+
+	p := &s
+	if err := d.Decode(&p); err != nil || p != nil {
+		t.Errorf("Invalid result. Function returned %v, %v.", p, err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		var n int
+		if err := d.Decode(&n); err != nil || n != 1 {
+			t.Fatalf("Invalid result. Function returned %v, %v.", n, err)
+		}
+	}
+
+	if err := d.Decode(&s); err != io.EOF {
+		t.Errorf("Invalid error. Function returned %v. Expected %v.", err, io.EOF)
+	}
+}
+
+func TestDecoder_Decode_Error(t *testing.T) {
+	d := NewDecoder(bytes.NewReader([]byte{0x92, 0x01}))
+	var i []int
+	if err := d.Decode(&i); err != io.ErrUnexpectedEOF {
+		t.Errorf("Invalid error. Function returned %v. Expected %v.", err, io.ErrUnexpectedEOF)
+	}
+
+	d = NewDecoder(bytes.NewReader([]byte{0x01, 0xC1}))
+	if err := d.Decode(&i); err == nil {
+		t.Error("Error was expected.")
+	}
+	var syntax SyntaxError
+	if err := d.Decode(&i); !errors.As(err, &syntax) {
+		t.Errorf("SyntaxError was expected. Function returned %v.", err)
+	}
+
+	readErr := errors.New("read error")
+	d = NewDecoder(iotest.ErrReader(readErr))
+	if err := d.Decode(&i); err != readErr {
+		t.Errorf("Invalid error. Function returned %v. Expected %v.", err, readErr)
+	}
+}
+
+func TestDecoder_SetCompatMode(t *testing.T) {
+	input := mustParseDiag(t, `"abc" "abc" "abc" {"key": "value"}`)
+	d := NewDecoder(bytes.NewReader(input))
+	d.SetCompatMode(true)
+
+	var b []byte
+	if err := d.Decode(&b); err != nil || !bytes.Equal(b, []byte("abc")) {
+		t.Errorf("Invalid result. Function returned %v, %v.", b, err)
+	}
+
+	var a [2]byte
+	if err := d.Decode(&a); err != nil || a != [2]byte{'a', 'b'} {
+		t.Errorf("Invalid result. Function returned %v, %v.", a, err)
+	}
+
+	var s string
+	if err := d.Decode(&s); err != nil || s != "abc" {
+		t.Errorf("Invalid result. Function returned %v, %v.", s, err)
+	}
+
+	// The map keys remain strings
+	var i interface{}
+	if err := d.Decode(&i); err != nil {
+		t.Fatalf("Decoder Error: %v", err)
+	}
+	expected := map[string]interface{}{"key": []byte("value")}
+	if !reflect.DeepEqual(i, expected) {
+		t.Errorf("Invalid result. Function returned %#v. Expected %#v.", i, expected)
+	}
+}
+
+func TestDecoder_SetNoCopy(t *testing.T) {
+	input := mustParseDiag(t, `{"a": h'0102'} {"a": h'0304'}`)
+	d := NewDecoder(iotest.HalfReader(bytes.NewReader(input)))
+	d.SetNoCopy(true)
+	d.SetInternKeys(true)
+
+	var first, second map[string][]byte
+	if err := d.Decode(&first); err != nil {
+		t.Fatalf("Decoder Error: %v", err)
+	}
+	if err := d.Decode(&second); err != nil {
+		t.Fatalf("Decoder Error: %v", err)
+	}
+
+	// The first payload is still valid after the next call
+	if !bytes.Equal(first["a"], []byte{0x01, 0x02}) || !bytes.Equal(second["a"], []byte{0x03, 0x04}) {
+		t.Errorf("Invalid result. Function returned %v, %v.", first, second)
+	}
+}
+
+func TestDecoder_SetDuplicateKeys(t *testing.T) {
+	type Test struct {
+		A int            `sbor:"a"`
+		B int            `sbor:"b"`
+		M map[string]int `sbor:"m"`
+	}
+
+	input := mustParseDiag(t, `{"a": 1, "b": 2, "a": 3}`)
+	fields := mustParseDiag(t, `{"m": {"x": 1}, "m": {"y": 2}}`)
+	nested := mustParseDiag(t, `{"n": [{1: "a", 2: "b", 1: "c"}]}`)
+
+	data := []struct {
+		policy  DuplicatePolicy
+		m       map[string]int
+		s       Test
+		fields  Test
+		ordered OrderedMap
+		iface   interface{}
+		nested  map[string]interface{}
+		name    string
+	}{
+		{DuplicateAllow, map[string]int{"a": 3, "b": 2}, Test{A: 3, B: 2}, Test{M: map[string]int{"x": 1, "y": 2}},
+			OrderedMap{{"a", uint64(1)}, {"b", uint64(2)}, {"a", uint64(3)}},
+			map[string]interface{}{"a": uint64(3), "b": uint64(2)},
+			map[string]interface{}{"n": []interface{}{map[interface{}]interface{}{uint64(1): "c", uint64(2): "b"}}}, "allow"},
+		{DuplicateFirst, map[string]int{"a": 1, "b": 2}, Test{A: 1, B: 2}, Test{M: map[string]int{"x": 1}},
+			OrderedMap{{"a", uint64(1)}, {"b", uint64(2)}},
+			map[string]interface{}{"a": uint64(1), "b": uint64(2)},
+			map[string]interface{}{"n": []interface{}{map[interface{}]interface{}{uint64(1): "a", uint64(2): "b"}}}, "first"},
+		{DuplicateLast, map[string]int{"a": 3, "b": 2}, Test{A: 3, B: 2}, Test{M: map[string]int{"y": 2}},
+			OrderedMap{{"b", uint64(2)}, {"a", uint64(3)}},
+			map[string]interface{}{"a": uint64(3), "b": uint64(2)},
+			map[string]interface{}{"n": []interface{}{map[interface{}]interface{}{uint64(1): "c", uint64(2): "b"}}}, "last"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var m map[string]int
+			var s, f Test
+			var ordered OrderedMap
+			var iface interface{}
+			var n map[string]interface{}
+			stream := append(append(append(append(input, input...), input...), fields...), input...)
+			d := NewDecoder(bytes.NewReader(append(stream, nested...)))
+			d.SetDuplicateKeys(test.policy)
+
+			if err := d.Decode(&m); err != nil || !reflect.DeepEqual(m, test.m) {
+				t.Errorf("Invalid result. Function returned %v, %v. Expected %v.", m, err, test.m)
+			}
+			if err := d.Decode(&s); err != nil || !reflect.DeepEqual(s, test.s) {
+				t.Errorf("Invalid result. Function returned %+v, %v. Expected %+v.", s, err, test.s)
+			}
+			if err := d.Decode(&ordered); err != nil || !reflect.DeepEqual(ordered, test.ordered) {
+				t.Errorf("Invalid result. Function returned %v, %v. Expected %v.", ordered, err, test.ordered)
+			}
+			if err := d.Decode(&f); err != nil || !reflect.DeepEqual(f, test.fields) {
+				t.Errorf("Invalid result. Function returned %+v, %v. Expected %+v.", f, err, test.fields)
+			}
+			if err := d.Decode(&iface); err != nil || !reflect.DeepEqual(iface, test.iface) {
+				t.Errorf("Invalid result. Function returned %v, %v. Expected %v.", iface, err, test.iface)
+			}
+			if err := d.Decode(&n); err != nil || !reflect.DeepEqual(n, test.nested) {
+				t.Errorf("Invalid result. Function returned %v, %v. Expected %v.", n, err, test.nested)
+			}
+		})
+	}
+
+	// The error reports the repeated key and its offset
+	invalid := []struct {
+		input  string
+		target interface{}
+		key    interface{}
+	}{
+		{input: `{"a": 1, "b": 2, "a": 3}`, target: new(map[string]int), key: "a"},
+		{input: `{"a": 1, "b": 2, "a": 3}`, target: new(Test), key: "a"},
+		{input: `{"a": 1, "b": 2, "a": 3}`, target: new(OrderedMap), key: "a"},
+		{input: `{1: true, 2_u16: true, 1_u16: true}`, target: new(map[int8]bool), key: int8(1)},
+		{input: `{"a": 1, "b": 2, "a": 3}`, target: new(interface{}), key: "a"},
+		{input: `{"a": {"b": 1, "b": 2}}`, target: new(map[string]interface{}), key: "b"},
+		{input: `{"x": [{1: 2, 1: 3}]}`, target: new(interface{}), key: uint64(1)},
+	}
+	for _, test := range invalid {
+		d := NewDecoder(bytes.NewReader(mustParseDiag(t, test.input)))
+		d.SetDuplicateKeys(DuplicateError)
+
+		var duplicated DuplicatedKeyError
+		err := d.Decode(test.target)
+		if !errors.As(err, &duplicated) || duplicated.Key != test.key || duplicated.Offset != 7 {
+			t.Errorf("DuplicatedKeyError was expected for the key %v at offset 7. Function returned %v.", test.key, err)
+		}
+	}
+}
+
+func TestDecoder_DisallowUnknownFields(t *testing.T) {
+	type Test struct {
+		Keys   map[string]interface{} `sbor:",setcustomkeys"`
+		Name   string                 `sbor:"name"`
+		Custom bool                   `sbor:"c,customkey"`
+		Hidden int                    `sbor:"-"`
+	}
+
+	type Array struct {
+		A int `sbor:",structarray"`
+		B string
+	}
+
+	data := []struct {
+		input  string
+		target interface{}
+		valid  bool
+		name   string
+	}{
+		{input: `{"name": "n", 1: true}`, target: &Test{Keys: map[string]interface{}{"c": 1}}, valid: true, name: "known keys"},
+		{input: `{"name": "n", "other": 1}`, target: &Test{Keys: map[string]interface{}{"c": 1}}, valid: false, name: "unknown key"},
+		{input: `{"Hidden": 1}`, target: &Test{Keys: map[string]interface{}{"c": 1}}, valid: false, name: "omitted field"},
+		{input: `{2: true}`, target: &Test{Keys: map[string]interface{}{"c": 1}}, valid: false, name: "unknown custom key"},
+		{input: `[1, "b"]`, target: &Array{}, valid: true, name: "array"},
+		{input: `[1, "b", 3]`, target: &Array{}, valid: false, name: "longer array"},
+		{input: `[1]`, target: &Array{}, valid: false, name: "shorter array"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(mustParseDiag(t, test.input)))
+			d.DisallowUnknownFields()
+			err := d.Decode(test.target)
+
+			var unknown UnknownFieldError
+			var typeError UnmarshalTypeError
+			switch {
+			case test.valid && err != nil:
+				t.Errorf("Decoder Error: %v", err)
+			case !test.valid && !errors.As(err, &unknown) && !errors.As(err, &typeError):
+				t.Errorf("UnknownFieldError or UnmarshalTypeError was expected. Function returned %v.", err)
+			}
+		})
+	}
+
+	// The error reports the key and its offset
+	var unknown UnknownFieldError
+	d := NewDecoder(bytes.NewReader(mustParseDiag(t, `{"name": "n", "other": 1}`)))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&Test{Keys: map[string]interface{}{"c": 1}}); !errors.As(err, &unknown) || unknown.Key != "other" || unknown.Offset != 8 {
+		t.Errorf("UnknownFieldError was expected for the key other at offset 8. Function returned %v.", err)
+	}
+}
+
+func TestDecoder_SetExternalType(t *testing.T) {
+	type Test struct {
+		Custom  TestExternalCustom
+		Pointer *TestExternalCustom
+		Nil     *TestExternalCustom
+		Large   []byte
+	}
+
+	large := bytes.Repeat([]byte{0xAB}, 70000)
+	stream := CustomEncoder{
+		Size: func(i interface{}) (int, error) {
+			return len(i.([]byte)), nil
+		},
+		Write: func(i interface{}, w io.Writer) error {
+			// Written in two parts, without building the payload
+			half := len(i.([]byte)) / 2
+			if _, err := w.Write(i.([]byte)[:half]); err != nil {
+				return err
+			}
+			_, err := w.Write(i.([]byte)[half:])
+			return err
+		},
+	}
+
+	var b bytes.Buffer
+	e := NewEncoder(&b)
+	if err := e.SetExternalType(0x10, &TestExternalCustom{}); err != nil {
+		t.Fatalf("Set External Error: %v", err)
+	}
+	if err := e.SetExternalType(0x11, []byte(nil), stream); err != nil {
+		t.Fatalf("Set External Error: %v", err)
+	}
+
+	input := Test{Custom: TestExternalCustom{"a"}, Pointer: &TestExternalCustom{"b"}, Large: large}
+	if err := e.Encode(input); err != nil {
+		t.Fatalf("Encoder Error: %v", err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte{0xC9, 0x00, 0x01, 0x11, 0x70, 0x11}) {
+		t.Error("The large payload isn't written with ext 32.")
+	}
+
+	var sizes []int
+	d := NewDecoder(&b)
+	if err := d.SetExternalType(0x10, TestExternalCustom{}); err != nil {
+		t.Fatalf("Set External Error: %v", err)
+	}
+	if err := d.SetExternalType(0x11, []byte(nil), CustomDecoder{
+		Reader: func(r io.Reader, size int, v interface{}) error {
+			sizes = append(sizes, size)
+			data, err := io.ReadAll(r)
+			*v.(*[]byte) = data
+			return err
+		},
+	}); err != nil {
+		t.Fatalf("Set External Error: %v", err)
+	}
+
+	var result Test
+	if err := d.Decode(&result); err != nil {
+		t.Fatalf("Decoder Error: %v", err)
+	}
+	if !reflect.DeepEqual(result, input) {
+		t.Errorf("Invalid result. Function returned %+v. Expected %+v.", result, input)
+	}
+	if !reflect.DeepEqual(sizes, []int{len(large)}) {
+		t.Errorf("Invalid sizes. Function returned %v. Expected %v.", sizes, []int{len(large)})
+	}
+}
+
+func TestDecoder_SetExternalType_Error(t *testing.T) {
+	d := NewDecoder(bytes.NewReader(mustParseDiag(t, `ext(2, h'01') "a"`)))
+	if err := d.SetExternalType(0x10, 0); err == nil {
+		t.Error("Error was expected without CustomDecoder.")
+	}
+	if err := d.SetExternalType(-1, TestExternalCustom{}); err == nil {
+		t.Error("Error was expected with a negative code.")
+	}
+	if err := d.SetExternalType(0x01, TestExternalCustom{}); err != nil {
+		t.Fatalf("Set External Error: %v", err)
+	}
+
+	// A different code, or an object that is not an external type
+	var typeError UnmarshalTypeError
+	for i := 0; i < 2; i++ {
+		var result TestExternalCustom
+		if err := d.Decode(&result); !errors.As(err, &typeError) {
+			t.Errorf("UnmarshalTypeError was expected. Function returned %v.", err)
+		}
+	}
+
+	decodeErr := errors.New("decode error")
+	config, err := NewConfig().WithExternalDecoder(0x02, 0, CustomDecoder{
+		Decoder: func(data []byte, v interface{}) error {
+			return decodeErr
+		},
+	})
+	if err != nil {
+		t.Fatalf("WithExternalDecoder Error: %v", err)
+	}
+	var i int
+	if err = config.Unmarshal(mustParseDiag(t, `ext(2, h'01')`), &i); err != decodeErr {
+		t.Errorf("Invalid error. Function returned %v. Expected %v.", err, decodeErr)
+	}
+}
@@ -1,7 +1,6 @@
 package sbor
 
 import (
-	"bytes"
 	"github.com/ErikPelli/sbor/internal/encode"
 	"github.com/ErikPelli/sbor/internal/utils"
 	"io"
@@ -26,10 +25,87 @@ type MessagePackCustom interface {
 // To get the type you should do a type assertion without checking for the success,
 // because this function will be used only with the associated type.
 // Ex: value := i.(complex64)
+//
+// For a large payload, Encoder can be replaced with Size and Write, so that the payload
+// doesn't have to be materialized in a byte slice. Size returns the length of the payload,
+// that is needed to write the header of the external type before the payload, and Write
+// writes exactly that number of bytes to w, that is the output of the encoder.
+// Marshal and Encoder.Encode still keep the whole message in memory before returning it,
+// but the payload is written directly into it.
 type CustomEncoder struct {
 	Encoder func(i interface{}) ([]byte, error)
+	Size    func(i interface{}) (int, error)
+	Write   func(i interface{}, w io.Writer) error
+}
+
+// handler returns the handler of the external type id that uses the functions of c.
+func (c CustomEncoder) handler(id int8) encode.ExtUserHandler {
+	return encode.ExtUserHandler{
+		Type:    byte(id),
+		Encoder: c.Encoder,
+		Size:    c.Size,
+		Writer:  c.Write,
+	}
 }
 
+// FloatPolicy chooses the format of the floating point numbers written by an Encoder.
+type FloatPolicy = encode.FloatPolicy
+
+// The float policies.
+const (
+	// FloatShortest writes a float32 when it represents the value exactly, else a float64.
+	// It's the default policy.
+	FloatShortest = encode.FloatShortest
+	// FloatPreserve writes a float32 for float32 values and a float64 for float64 values.
+	FloatPreserve = encode.FloatPreserve
+	// FloatAlways64 writes always a float64.
+	FloatAlways64 = encode.FloatAlways64
+)
+
+// IntPolicy chooses the format of the integers written by an Encoder.
+type IntPolicy = encode.IntPolicy
+
+// The integer policies.
+const (
+	// IntShortest writes the smallest format that contains the value, a positive fixint
+	// for the integers between 0 and 127. It's the default policy.
+	IntShortest = encode.IntShortest
+	// IntPreserve writes the format with the width of the Go type, int for the signed
+	// types and uint for the unsigned ones, so an int16 is always written as int 16.
+	IntPreserve = encode.IntPreserve
+)
+
+// TimePolicy chooses the format of the time.Time values written by an Encoder.
+type TimePolicy = encode.TimePolicy
+
+// The time policies.
+const (
+	// TimeExt writes the timestamp external type, with type code -1. It's the default policy.
+	TimeExt = encode.TimeExt
+	// TimeUnix writes an integer number of seconds since the Unix epoch, discarding the
+	// fractions of a second.
+	TimeUnix = encode.TimeUnix
+	// TimeUnixMilli writes an integer number of milliseconds since the Unix epoch.
+	TimeUnixMilli = encode.TimeUnixMilli
+	// TimeUnixNano writes an integer number of nanoseconds since the Unix epoch, that
+	// represents only the years between 1678 and 2262.
+	TimeUnixNano = encode.TimeUnixNano
+	// TimeUnixFloat writes a floating point number of seconds since the Unix epoch, with
+	// about a microsecond of precision for the current dates.
+	TimeUnixFloat = encode.TimeUnixFloat
+	// TimeRFC3339 writes a string in the RFC 3339 format, with the nanoseconds if they
+	// aren't zero, like "2006-01-02T15:04:05.999999999Z07:00".
+	TimeRFC3339 = encode.TimeRFC3339
+	// TimeZoned writes an external type with the seconds since the Unix epoch, the nanoseconds
+	// and the time zone, that is the IANA name of the location along with its offset from UTC,
+	// so that the decoded time.Time has the same location. See Encoder.SetZonedTimeType.
+	TimeZoned = encode.TimeZoned
+)
+
+// DefaultZonedTimeType is the external type code of the times written with TimeZoned,
+// unless changed with SetZonedTimeType.
+const DefaultZonedTimeType = encode.DefaultZonedTimeType
+
 // An Encoder writes MessagePack values to an output stream.
 type Encoder struct {
 	w     io.Writer
@@ -55,10 +131,56 @@ func NewEncoder(w io.Writer) *Encoder {
 //
 // If value implements MessagePackCustom interface, the corresponding method MarshalMsgpack will be used
 // for encoding, else you have to provide a CustomEncoder function.
+//
+// A type registered as a pointer matches also its values, and a type registered as a value
+// matches also the non-nil pointers to it, that are dereferenced. When several registrations
+// match a value, the one used is chosen in this order:
+//
+//   1. the type of the value, registered with SetExternalType
+//   2. the pointer or the value counterpart of the type, registered with SetExternalType
+//   3. the interfaces implemented by the type, registered with SetExternalInterface, in order of registration
+//
+// The timestamp external type of time.Time is replaced only by a registration of time.Time itself.
 func (e *Encoder) SetExternalType(id int8, value interface{}, c ...CustomEncoder) error {
+	return setExternalType(e.state, id, value, c...)
+}
+
+// SetExternalInterface associate an interface with an external type code, for this Encoder,
+// so that all the types that implement the interface are encoded with that code.
+// The interface is passed as a nil pointer to it, for example (*Money)(nil).
+//
+// If the interface embeds MessagePackCustom, the method MarshalMsgpack will be used for encoding,
+// else you have to provide a CustomEncoder function, that receives the value that implements it.
+// See the documentation of SetExternalType for the order of precedence of the registrations.
+func (e *Encoder) SetExternalInterface(id int8, pointerToInterface interface{}, c ...CustomEncoder) error {
+	return setExternalInterface(e.state, id, pointerToInterface, c...)
+}
+
+// setExternalInterface associate an interface with an external type code in state.
+func setExternalInterface(state *encode.EncoderState, id int8, pointerToInterface interface{}, c ...CustomEncoder) error {
+	t := reflect.TypeOf(pointerToInterface)
+	if t != nil && t.Kind() == reflect.Ptr && t.Elem().Implements(reflect.TypeOf((*MessagePackCustom)(nil)).Elem()) {
+		return state.SetExternalInterfaceHandler(pointerToInterface, encode.ExtUserHandler{
+			Type: byte(id),
+			Encoder: func(i interface{}) ([]byte, error) {
+				encoder := i.(MessagePackCustom)
+				return encoder.MarshalMsgpack()
+			},
+		})
+	}
+
+	if len(c) != 1 {
+		return utils.InvalidArgumentError{Desc: "CustomEncoder expected"}
+	}
+
+	return state.SetExternalInterfaceHandler(pointerToInterface, c[0].handler(id))
+}
+
+// setExternalType associate a type with an external type code in state.
+func setExternalType(state *encode.EncoderState, id int8, value interface{}, c ...CustomEncoder) error {
 	_, customInterface := value.(MessagePackCustom)
 	if customInterface {
-		return e.state.SetExternalTypeHandler(value, encode.ExtUserHandler{
+		return state.SetExternalTypeHandler(value, encode.ExtUserHandler{
 			Type: byte(id),
 			Encoder: func(i interface{}) ([]byte, error) {
 				encoder := i.(MessagePackCustom)
@@ -71,21 +193,101 @@ func (e *Encoder) SetExternalType(id int8, value interface{}, c ...CustomEncoder
 		return utils.InvalidArgumentError{Desc: "CustomEncoder expected"}
 	}
 
-	return e.state.SetExternalTypeHandler(value, encode.ExtUserHandler{
-		Type:    byte(id),
-		Encoder: c[0].Encoder,
-	})
+	return state.SetExternalTypeHandler(value, c[0].handler(id))
+}
+
+// SetTagName sets the keys of the struct field's tag read by this Encoder, in order of precedence.
+// For every field the first key present in its tag is used, and the options of the other
+// keys are ignored, so SetTagName("sbor", "msgpack", "json") lets a struct annotated only
+// for encoding/json be encoded with the same names, while a "sbor" tag still has the last word.
+// The options are taken from the same key as the name and keep their meaning whatever the key:
+// "-" omits the field, "omitempty" omits an empty value, "omitzero" omits a zero value and "string"
+// writes a boolean or a number as a string, as in encoding/json, while the options unknown to this
+// package are ignored.
+// Without arguments it restores the default, that is only the "sbor" key.
+func (e *Encoder) SetTagName(names ...string) {
+	e.state.TagNames = tagNames(names)
+}
+
+// tagNames returns a copy of the keys of the struct field's tag, or the default ones if empty.
+func tagNames(names []string) []string {
+	if len(names) == 0 {
+		names = encode.DefaultTagNames
+	}
+	return append([]string(nil), names...)
+}
+
+// SetFloatPolicy sets the format of the floating point numbers written by this Encoder.
+// A struct field can override it with the "float=shortest", "float=preserve" or "float=64"
+// option, that applies to all the numbers in the field value.
+func (e *Encoder) SetFloatPolicy(p FloatPolicy) {
+	e.state.Numbers.Float = p
+}
+
+// SetIntPolicy sets the format of the integers written by this Encoder.
+// A struct field can override it with the "int=shortest" or "int=preserve" option,
+// that applies to all the numbers in the field value.
+func (e *Encoder) SetIntPolicy(p IntPolicy) {
+	e.state.Numbers.Int = p
+}
+
+// SetTimePolicy sets the format of the time.Time values written by this Encoder, for the readers
+// that don't support the timestamp external type or to keep the time zone. A struct field can
+// override it with the "time=ext", "time=unix", "time=unixmilli", "time=unixnano", "time=unixfloat",
+// "time=rfc3339" or "time=zoned" option, that applies to all the times in the field value. The numbers are written with the
+// number policies, and a policy different from TimeExt applies also if time.Time is associated
+// with an external type.
+func (e *Encoder) SetTimePolicy(p TimePolicy) {
+	e.state.Time = p
+}
+
+// SetZonedTimeType sets the external type code of the times written by this Encoder with
+// the TimeZoned policy, between 0 and 127. The default is DefaultZonedTimeType.
+//
+// The payload contains the seconds since the Unix epoch as int64, the nanoseconds as uint32,
+// the offset of the zone from UTC in seconds as int32, all big-endian, and then the IANA name
+// of the location, like "Europe/Rome". The name is empty for time.Local, that depends on the
+// machine, so these times are decoded with a fixed zone.
+func (e *Encoder) SetZonedTimeType(id int8) error {
+	if id < 0 {
+		return utils.OutOfBoundError{Key: int(id)}
+	}
+	e.state.ZonedTimeType = id
+	return nil
+}
+
+// SetPreserveSign sets whether the values of the signed Go integer types are always written
+// with the int formats, so that a reader can tell them from the unsigned ones. With IntShortest
+// a non-negative value is written with the smallest int format instead of a positive fixint or
+// uint format, for example 5 becomes int 8. IntPreserve already preserves the sign.
+// A struct field can enable it with the "signed" option.
+func (e *Encoder) SetPreserveSign(preserve bool) {
+	e.state.Numbers.PreserveSign = preserve
+}
+
+// SetCompatMode sets whether this Encoder writes only the formats of the old MessagePack
+// specification, for readers that predate the str8, bin and ext formats. In compatibility mode
+// the strings use the fixstr, str 16 and str 32 formats, []byte values are written as raw strings
+// with the same formats, and time.Time values and the external types return an error.
+// Values of type Value and RawMessage are written as they are.
+func (e *Encoder) SetCompatMode(compat bool) {
+	e.state.CompatMode = compat
+}
+
+// SetCanonical sets whether this Encoder sorts the entries of the Go maps by the bytewise
+// lexicographic order of the encoding of their keys, so that equal values are always encoded
+// in the same way, for example to sign or hash them. The struct fields are written in their
+// declaration order anyway.
+func (e *Encoder) SetCanonical(canonical bool) {
+	e.state.Canonical = canonical
 }
 
 // Encode writes the MessagePack encoding of v to the stream.
 // See the documentation for Marshal for details about the conversion of Go values to MessagePack.
 func (e *Encoder) Encode(v interface{}) error {
-	result := e.state.TypeWrapper(reflect.ValueOf(v))
-	bufferResult := bytes.NewBuffer(make([]byte, 0, result.Len()))
-	_, err := result.WriteTo(bufferResult)
-
+	result, err := marshal(e.state, v)
 	if err == nil {
-		_, err = e.w.Write(bufferResult.Bytes())
+		_, err = e.w.Write(result)
 	}
 
 	return err
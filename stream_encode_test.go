@@ -5,6 +5,8 @@ import (
 	"encoding/binary"
 	"math"
 	"testing"
+	"time"
+	_ "time/tzdata"
 )
 
 type TestExternalCustom struct {
@@ -80,6 +82,54 @@ func TestEncoder_SetExternalType_CustomEncoder(t *testing.T) {
 	}
 }
 
+type testMoney interface {
+	Cents() int64
+}
+
+type testEuro int64
+
+func (e testEuro) Cents() int64 {
+	return int64(e)
+}
+
+func TestEncoder_SetExternalInterface(t *testing.T) {
+	var b bytes.Buffer
+	e := NewEncoder(&b)
+
+	if err := e.SetExternalInterface(0x05, (*testMoney)(nil), CustomEncoder{
+		Encoder: func(i interface{}) ([]byte, error) {
+			return []byte{byte(i.(testMoney).Cents())}, nil
+		},
+	}); err != nil {
+		t.Errorf("Set External Error: %v", err)
+	}
+
+	// The value registered as a pointer matches also the value
+	if err := e.SetExternalType(0x10, &TestExternalCustom{}); err != nil {
+		t.Errorf("Set External Error: %v", err)
+	}
+
+	input := []interface{}{testEuro(7), TestExternalCustom{"a"}, &TestExternalCustom{"b"}}
+	if err := e.Encode(input); err != nil {
+		t.Errorf("Encoder Error: %v", err)
+	}
+
+	expected := mustParseDiag(t, "[ext(5, h'07'), ext(16, h'61'), ext(16, h'62')]")
+	if !bytes.Equal(b.Bytes(), expected) {
+		t.Errorf("Encoder output different than expected. Returned %v. Expected %v.", b.Bytes(), expected)
+	}
+
+	if err := e.SetExternalInterface(0x05, (*testMoney)(nil)); err == nil {
+		t.Error("Error was expected without CustomEncoder.")
+	}
+	if err := e.SetExternalInterface(0x05, (*MessagePackCustom)(nil)); err != nil {
+		t.Errorf("Set External Error: %v", err)
+	}
+	if _, err := NewConfig().WithExternalInterface(0x05, testEuro(0)); err == nil {
+		t.Error("Error was expected with a type that is not an interface.")
+	}
+}
+
 func TestEncoder_SetExternalType_Error(t *testing.T) {
 	var b bytes.Buffer
 	e := NewEncoder(&b)
@@ -88,3 +138,173 @@ func TestEncoder_SetExternalType_Error(t *testing.T) {
 		t.Errorf("Set External Error: %v", err)
 	}
 }
+
+func TestEncoder_NumberPolicies(t *testing.T) {
+	input := []interface{}{1.5, float32(1.5), int16(5), uint8(5), int64(-1)}
+
+	data := []struct {
+		floatPolicy  FloatPolicy
+		intPolicy    IntPolicy
+		preserveSign bool
+		expected     string
+		name         string
+	}{
+		{FloatShortest, IntShortest, false, "[1.5f32, 1.5f32, 5, 5, -1]", "default"},
+		{FloatPreserve, IntPreserve, false, "[1.5f64, 1.5f32, 5_i16, 5_u8, -1_i64]", "preserve"},
+		{FloatAlways64, IntShortest, true, "[1.5f64, 1.5f64, 5_i8, 5, -1]", "always 64 and signed"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			e := NewEncoder(&b)
+			e.SetFloatPolicy(test.floatPolicy)
+			e.SetIntPolicy(test.intPolicy)
+			e.SetPreserveSign(test.preserveSign)
+
+			if err := e.Encode(input); err != nil {
+				t.Fatalf("Encoder Error: %v", err)
+			}
+
+			expected := mustParseDiag(t, test.expected)
+			if !bytes.Equal(b.Bytes(), expected) {
+				t.Errorf("Encoder output different than expected. Returned %v. Expected %v.", b.Bytes(), expected)
+			}
+		})
+	}
+}
+
+func TestEncoder_SetTimePolicy(t *testing.T) {
+	type Test struct {
+		A time.Time
+		B time.Time `sbor:",time=rfc3339"`
+	}
+
+	instant := time.Date(2022, 3, 6, 15, 20, 0, 500000000, time.UTC)
+	input := Test{A: instant, B: instant}
+
+	data := []struct {
+		policy   TimePolicy
+		expected string
+		name     string
+	}{
+		{TimeExt, `{"A": ts("2022-03-06T15:20:00.5Z"), "B": "2022-03-06T15:20:00.5Z"}`, "ext"},
+		{TimeUnix, `{"A": 1646580000_i32, "B": "2022-03-06T15:20:00.5Z"}`, "unix"},
+		{TimeUnixMilli, `{"A": 1646580000500_i64, "B": "2022-03-06T15:20:00.5Z"}`, "unix milliseconds"},
+		{TimeUnixNano, `{"A": 1646580000500000000_i64, "B": "2022-03-06T15:20:00.5Z"}`, "unix nanoseconds"},
+		{TimeUnixFloat, `{"A": 1646580000.5, "B": "2022-03-06T15:20:00.5Z"}`, "unix float"},
+		{TimeRFC3339, `{"A": "2022-03-06T15:20:00.5Z", "B": "2022-03-06T15:20:00.5Z"}`, "rfc3339"},
+	}
+
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var b bytes.Buffer
+			e := NewEncoder(&b)
+			e.SetTimePolicy(test.policy)
+
+			if err := e.Encode(input); err != nil {
+				t.Fatalf("Encoder Error: %v", err)
+			}
+
+			expected := mustParseDiag(t, test.expected)
+			if !bytes.Equal(b.Bytes(), expected) {
+				t.Errorf("Encoder output different than expected. Returned %v. Expected %v.", b.Bytes(), expected)
+			}
+
+			// The decoder reads every format
+			var result Test
+			d := NewDecoder(&b)
+			d.SetTimePolicy(test.policy)
+			if err := d.Decode(&result); err != nil {
+				t.Fatalf("Decoder Error: %v", err)
+			}
+			if test.policy != TimeUnix && !result.A.Equal(instant) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result.A, instant)
+			}
+			if !result.B.Equal(instant) {
+				t.Errorf("Invalid result. Function returned %v. Expected %v.", result.B, instant)
+			}
+		})
+	}
+}
+
+func TestEncoder_SetZonedTimeType(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	if err != nil {
+		t.Fatalf("LoadLocation Error: %v", err)
+	}
+	input := time.Date(2022, 7, 1, 12, 30, 0, 5, rome)
+
+	var b bytes.Buffer
+	e := NewEncoder(&b)
+	e.SetTimePolicy(TimeZoned)
+	if err = e.Encode(input); err != nil {
+		t.Fatalf("Encoder Error: %v", err)
+	}
+	if err = e.SetZonedTimeType(100); err != nil {
+		t.Fatalf("SetZonedTimeType Error: %v", err)
+	}
+	if err = e.Encode(input); err != nil {
+		t.Fatalf("Encoder Error: %v", err)
+	}
+
+	expected := mustParseDiag(t, "ext(89, h'0000000062becca80000000500001c204575726f70652f526f6d65') ext(100, h'0000000062becca80000000500001c204575726f70652f526f6d65')")
+	if !bytes.Equal(b.Bytes(), expected) {
+		t.Errorf("Encoder output different than expected. Returned %v. Expected %v.", b.Bytes(), expected)
+	}
+
+	// The location is preserved, and the code must match
+	var result time.Time
+	d := NewDecoder(&b)
+	if err = d.Decode(&result); err != nil {
+		t.Fatalf("Decoder Error: %v", err)
+	}
+	if !result.Equal(input) || result.Location().String() != "Europe/Rome" {
+		t.Errorf("Invalid result. Function returned %v. Expected %v.", result, input)
+	}
+	if err = d.Decode(&result); err == nil {
+		t.Error("Error was expected with a different external type code.")
+	}
+
+	if err = e.SetZonedTimeType(-1); err == nil {
+		t.Error("Error was expected with a negative external type code.")
+	}
+	if err = d.SetZonedTimeType(-1); err == nil {
+		t.Error("Error was expected with a negative external type code.")
+	}
+}
+
+func TestEncoder_SetCompatMode(t *testing.T) {
+	type Test struct {
+		Text  string
+		Bytes []byte
+		Count int `sbor:",string"`
+	}
+
+	long := string(bytes.Repeat([]byte{'a'}, 40))
+	var b bytes.Buffer
+	e := NewEncoder(&b)
+	e.SetCompatMode(true)
+	if err := e.Encode(Test{Text: long, Bytes: []byte{0x01, 0x02}, Count: 3}); err != nil {
+		t.Fatalf("Encoder Error: %v", err)
+	}
+
+	// The string of 40 bytes is written with str 16 instead of str 8
+	expected := []byte{0x83, 0xA4, 'T', 'e', 'x', 't', 0xDA, 0x00, 40}
+	expected = append(expected, long...)
+	expected = append(expected, 0xA5, 'B', 'y', 't', 'e', 's', 0xA2, 0x01, 0x02, 0xA5, 'C', 'o', 'u', 'n', 't', 0xA1, '3')
+	if !bytes.Equal(b.Bytes(), expected) {
+		t.Errorf("Encoder output different than expected. Returned %v. Expected %v.", b.Bytes(), expected)
+	}
+
+	if err := e.Encode(time.Unix(0, 0)); err == nil {
+		t.Error("Error was expected with time.Time.")
+	}
+
+	if err := e.SetExternalType(1, &TestExternalCustom{}); err != nil {
+		t.Fatalf("SetExternalType Error: %v", err)
+	}
+	if err := e.Encode(&TestExternalCustom{value: "x"}); err == nil {
+		t.Error("Error was expected with an external type.")
+	}
+}
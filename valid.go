@@ -0,0 +1,69 @@
+package sbor
+
+import (
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/utils"
+)
+
+// ValidOptions configures the checks done by Valid and ValidStream.
+// The zero value checks only the structure of the data.
+type ValidOptions struct {
+	// StrictUTF8 specifies that the payloads of all the strings must be valid UTF-8.
+	StrictUTF8 bool
+}
+
+// Valid checks that data contains exactly one well-formed MessagePack object, without
+// decoding it: all the headers and payloads must be complete, and the reserved 0xc1
+// byte must not be used. If data is invalid, a SyntaxError with the offset of the
+// offending byte is returned.
+//
+// Use ValidOptions to enable the additional checks.
+func Valid(data []byte) error {
+	return ValidOptions{}.Valid(data)
+}
+
+// ValidStream checks that data contains a sequence of zero or more concatenated
+// well-formed MessagePack objects. It returns the number of valid objects found
+// before an eventual error. See Valid for the details about the checks.
+func ValidStream(data []byte) (int, error) {
+	return ValidOptions{}.ValidStream(data)
+}
+
+// Valid checks that data contains exactly one well-formed MessagePack object,
+// using the options in o. See the Valid function for the details.
+func (o ValidOptions) Valid(data []byte) error {
+	end, err := o.skip(data, 0)
+	if err != nil {
+		return err
+	}
+
+	if end != len(data) {
+		return utils.SyntaxError{Offset: end, Desc: "unexpected data after the object"}
+	}
+	return nil
+}
+
+// ValidStream checks that data contains a sequence of concatenated well-formed
+// MessagePack objects, using the options in o. See the ValidStream function for the details.
+func (o ValidOptions) ValidStream(data []byte) (int, error) {
+	var objects, offset int
+
+	for offset < len(data) {
+		end, err := o.skip(data, offset)
+		if err != nil {
+			return objects, err
+		}
+
+		offset = end
+		objects++
+	}
+
+	return objects, nil
+}
+
+func (o ValidOptions) skip(data []byte, offset int) (int, error) {
+	if o.StrictUTF8 {
+		return decode.SkipValid(data, offset)
+	}
+	return decode.Skip(data, offset)
+}
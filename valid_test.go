@@ -0,0 +1,80 @@
+package sbor
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	data := newTestEnvelope(t)
+	if err := Valid(data); err != nil {
+		t.Errorf("Valid Error: %v", err)
+	}
+
+	strict := ValidOptions{StrictUTF8: true}
+	if err := strict.Valid(data); err != nil {
+		t.Errorf("Strict Valid Error: %v", err)
+	}
+
+	invalid := []struct {
+		input  []byte
+		offset int
+		name   string
+	}{
+		{input: []byte{}, offset: 0, name: "empty"},
+		{input: []byte{0x92, 0xC1, 0xC0}, offset: 1, name: "reserved byte"},
+		{input: []byte{0x92, 0xC0}, offset: 2, name: "missing element"},
+		{input: []byte{0x81, 0xA1, 0x61, 0xDA, 0x00, 0x05, 0x61}, offset: 3, name: "truncated payload"},
+		{input: []byte{0x81, 0xA1, 0x61, 0xCD, 0x01}, offset: 3, name: "truncated uint16"},
+		{input: []byte{0xC7, 0x01}, offset: 0, name: "truncated ext header"},
+		{input: []byte{0xC0, 0xC0}, offset: 1, name: "trailing data"},
+	}
+
+	for _, test := range invalid {
+		t.Run(test.name, func(t *testing.T) {
+			var syntax SyntaxError
+			if err := Valid(test.input); !errors.As(err, &syntax) || syntax.Offset != test.offset {
+				t.Errorf("Invalid error. Function returned %v. Expected offset %d.", err, test.offset)
+			}
+		})
+	}
+}
+
+func TestValid_StrictUTF8(t *testing.T) {
+	data := []byte{0x82, 0xA1, 0x61, 0xC0, 0xA2, 0xFF, 0x61, 0xC0}
+
+	if err := Valid(data); err != nil {
+		t.Errorf("Valid Error: %v", err)
+	}
+
+	var syntax SyntaxError
+	if err := (ValidOptions{StrictUTF8: true}).Valid(data); !errors.As(err, &syntax) || syntax.Offset != 5 {
+		t.Errorf("Invalid error. Function returned %v. Expected offset %d.", err, 5)
+	}
+}
+
+func TestValidStream(t *testing.T) {
+	data := append(newTestEnvelope(t), 0xC0, 0x92, 0x01, 0x02)
+
+	if n, err := ValidStream(data); err != nil || n != 3 {
+		t.Errorf("Invalid result. Function returned %d, %v. Expected %d.", n, err, 3)
+	}
+
+	if n, err := ValidStream(nil); err != nil || n != 0 {
+		t.Errorf("Invalid result. Function returned %d, %v. Expected %d.", n, err, 0)
+	}
+
+	if n, err := ValidStream(data[:len(data)-1]); err == nil || n != 2 {
+		t.Errorf("Invalid result. Function returned %d, %v. Expected %d and an error.", n, err, 2)
+	}
+}
+
+func TestRawMessage_Marshal_Invalid(t *testing.T) {
+	if _, err := Marshal(RawMessage{0x92, 0xC0}); err == nil {
+		t.Error("Invalid RawMessage error was expected.")
+	}
+
+	if _, err := Marshal(RawMessage{0xC0, 0xC0}); err == nil {
+		t.Error("RawMessage with multiple objects error was expected.")
+	}
+}
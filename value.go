@@ -0,0 +1,366 @@
+package sbor
+
+import (
+	"bytes"
+	"github.com/ErikPelli/sbor/internal/decode"
+	"github.com/ErikPelli/sbor/internal/encode"
+	"github.com/ErikPelli/sbor/internal/types"
+	"github.com/ErikPelli/sbor/internal/utils"
+	"reflect"
+	"time"
+)
+
+func init() {
+	// A Value inside a Go value is encoded as its tree
+	encode.RegisterBuiltin(Value{}, func(_ *encode.EncoderState, v reflect.Value) utils.MessagePackTypeEncoder {
+		return v.Interface().(Value).messagePackType()
+	})
+
+	// A Value inside a Go value is decoded as its tree
+	decode.RegisterBuiltin(Value{}, func(d *decode.DecoderState, data []byte, offset int, v reflect.Value, depth int) (int, error) {
+		t, end, err := d.Parse(data, offset, depth)
+		if err == nil {
+			v.Set(reflect.ValueOf(Value{t}))
+		}
+		return end, err
+	})
+}
+
+// Kind represents the family of a MessagePack value.
+type Kind = decode.Kind
+
+// The kinds of the MessagePack values.
+const (
+	InvalidKind = decode.Invalid
+	NilKind     = decode.Nil
+	BoolKind    = decode.Boolean
+	IntKind     = decode.Int
+	UintKind    = decode.Uint
+	FloatKind   = decode.Float
+	StringKind  = decode.String
+	BinaryKind  = decode.Binary
+	ArrayKind   = decode.Array
+	MapKind     = decode.Map
+	ExtKind     = decode.Ext
+)
+
+// Value is a MessagePack value of any kind, that can be built and inspected as a tree
+// without a Go type that represents it.
+// In this way it's possible to handle messages that no Go struct can represent,
+// like maps with keys of different types, or with duplicated keys in a specific order.
+//
+// Integers decoded from a positive fixint or uint format have UintKind, the ones decoded
+// from a negative fixint or int format have IntKind.
+//
+// A Value keeps the values of the objects but not their formats: Marshal writes every
+// object in the shortest format that holds it, so a round trip through Unmarshal and
+// Marshal isn't byte-exact when the data uses longer formats. For example a float 64
+// that holds 1.5 is written back as a float 32, and a uint 16 that holds 5 as a
+// positive fixint. Use RawMessage to keep the exact encoding of a part of a message.
+//
+// The zero Value has InvalidKind, and it can't be encoded.
+type Value struct {
+	t utils.MessagePackType
+}
+
+// Pair is a key-value association of a MessagePack map.
+type Pair struct {
+	Key   Value
+	Value Value
+}
+
+// NewNil returns the MessagePack nil.
+func NewNil() Value {
+	return Value{types.Nil{}}
+}
+
+// NewBool returns a MessagePack boolean.
+func NewBool(b bool) Value {
+	return Value{types.Boolean(b)}
+}
+
+// NewInt returns a MessagePack signed integer.
+func NewInt(i int64) Value {
+	return Value{types.Int(i)}
+}
+
+// NewUint returns a MessagePack unsigned integer.
+func NewUint(u uint64) Value {
+	return Value{types.Uint(u)}
+}
+
+// NewFloat returns a MessagePack floating point number.
+func NewFloat(f float64) Value {
+	return Value{types.Float(f)}
+}
+
+// NewString returns a MessagePack string.
+func NewString(s string) Value {
+	return Value{types.String(s)}
+}
+
+// NewBinary returns a MessagePack binary that contains data.
+func NewBinary(data []byte) Value {
+	return Value{types.Binary(data)}
+}
+
+// NewExt returns a MessagePack external type with the given type code and payload.
+func NewExt(typ int8, data []byte) Value {
+	return Value{types.External{Type: byte(typ), Data: data}}
+}
+
+// NewTimestamp returns a MessagePack timestamp (external type -1) that represents t.
+func NewTimestamp(t time.Time) Value {
+	return Value{encode.NewTimestamp(t)}
+}
+
+// NewArray returns a MessagePack array that contains the given elements.
+func NewArray(elements ...Value) Value {
+	array := make(types.Array, len(elements))
+	for i := range elements {
+		array[i] = elements[i].messagePackType()
+	}
+	return Value{array}
+}
+
+// NewMap returns a MessagePack map that contains the given key-value pairs,
+// in the same order and without removing duplicated keys.
+func NewMap(pairs ...Pair) Value {
+	m := make(types.Map, len(pairs))
+	for i := range pairs {
+		m[i].Key = pairs[i].Key.messagePackType()
+		m[i].Value = pairs[i].Value.messagePackType()
+	}
+	return Value{m}
+}
+
+// messagePackType returns the tree of v, or an error type if v is the zero Value.
+func (v Value) messagePackType() utils.MessagePackType {
+	if v.t == nil {
+		return utils.ErrorMessagePackType("invalid Value")
+	}
+	return v.t
+}
+
+// Kind returns the kind of v.
+func (v Value) Kind() Kind {
+	switch v.t.(type) {
+	case types.Nil:
+		return NilKind
+	case types.Boolean:
+		return BoolKind
+	case types.Int:
+		return IntKind
+	case types.Uint:
+		return UintKind
+	case types.Float:
+		return FloatKind
+	case types.String:
+		return StringKind
+	case types.Binary:
+		return BinaryKind
+	case types.Array:
+		return ArrayKind
+	case types.Map:
+		return MapKind
+	case types.External:
+		return ExtKind
+	default:
+		return InvalidKind
+	}
+}
+
+// Bool returns the value of a boolean, or false if v isn't a boolean.
+func (v Value) Bool() bool {
+	b, _ := v.t.(types.Boolean)
+	return bool(b)
+}
+
+// Int returns the value of an integer converted to int64, like a Go conversion would do.
+// It returns 0 if v isn't an integer.
+func (v Value) Int() int64 {
+	switch t := v.t.(type) {
+	case types.Int:
+		return int64(t)
+	case types.Uint:
+		return int64(t)
+	default:
+		return 0
+	}
+}
+
+// Uint returns the value of an integer converted to uint64, like a Go conversion would do.
+// It returns 0 if v isn't an integer.
+func (v Value) Uint() uint64 {
+	switch t := v.t.(type) {
+	case types.Int:
+		return uint64(t)
+	case types.Uint:
+		return uint64(t)
+	default:
+		return 0
+	}
+}
+
+// Float returns the value of a floating point number or of an integer, as a float64.
+// It returns 0 if v isn't a number.
+func (v Value) Float() float64 {
+	switch t := v.t.(type) {
+	case types.Float:
+		return float64(t)
+	case types.Int:
+		return float64(t)
+	case types.Uint:
+		return float64(t)
+	default:
+		return 0
+	}
+}
+
+// String returns the value of a string.
+// Unlike the other getters, it doesn't return the zero value if v isn't a string,
+// but a string of the form "<kind Value>", like reflect.Value does.
+func (v Value) String() string {
+	if s, ok := v.t.(types.String); ok {
+		return string(s)
+	}
+	return "<" + v.Kind().String() + " Value>"
+}
+
+// Bytes returns the content of a binary, or nil if v isn't a binary.
+func (v Value) Bytes() []byte {
+	b, _ := v.t.(types.Binary)
+	return b
+}
+
+// Ext returns the type code and the payload of an external type.
+// It returns 0 and nil if v isn't an external type.
+func (v Value) Ext() (int8, []byte) {
+	e, _ := v.t.(types.External)
+	return int8(e.Type), e.Data
+}
+
+// Len returns the number of elements of an array, the number of key-value
+// pairs of a map, or the length in bytes of a string, a binary or an external payload.
+// It returns 0 for the other kinds.
+func (v Value) Len() int {
+	switch t := v.t.(type) {
+	case types.Array:
+		return len(t)
+	case types.Map:
+		return len(t)
+	case types.String:
+		return len(t)
+	case types.Binary:
+		return len(t)
+	case types.External:
+		return len(t.Data)
+	default:
+		return 0
+	}
+}
+
+// Index returns the i-th element of an array.
+// It returns the zero Value if v isn't an array or i is out of range.
+func (v Value) Index(i int) Value {
+	array, _ := v.t.(types.Array)
+	if i < 0 || i >= len(array) {
+		return Value{}
+	}
+	return Value{array[i]}
+}
+
+// MapGet returns the value associated with key in a map, and whether it has been found.
+// Key can be a Value or any Go value, that is encoded as Marshal does.
+// Two keys are equal when their MessagePack encodings are equal, and if the key is
+// duplicated the first value is returned.
+func (v Value) MapGet(key interface{}) (Value, bool) {
+	m, _ := v.t.(types.Map)
+	if len(m) == 0 {
+		return Value{}, false
+	}
+
+	var keyType utils.MessagePackTypeEncoder
+	if keyValue, ok := key.(Value); ok {
+		keyType = keyValue.messagePackType()
+	} else {
+		keyType = encode.NewEncoderState().TypeWrapper(reflect.ValueOf(key))
+	}
+
+	var expected bytes.Buffer
+	if _, err := keyType.WriteTo(&expected); err != nil {
+		return Value{}, false
+	}
+
+	var current bytes.Buffer
+	for i := range m {
+		if m[i].Key.Len() != expected.Len() {
+			continue
+		}
+
+		current.Reset()
+		if _, err := m[i].Key.WriteTo(&current); err == nil && bytes.Equal(current.Bytes(), expected.Bytes()) {
+			return Value{m[i].Value}, true
+		}
+	}
+
+	return Value{}, false
+}
+
+// Iter returns an iterator over the key-value pairs of a map, in their order.
+// If v isn't a map the iterator is empty.
+func (v Value) Iter() *MapIter {
+	m, _ := v.t.(types.Map)
+	return &MapIter{m: m, i: -1}
+}
+
+// Marshal returns the MessagePack encoding of v, writing every object in its shortest format.
+func (v Value) Marshal() ([]byte, error) {
+	t := v.messagePackType()
+	bufferResult := bytes.NewBuffer(make([]byte, 0, t.Len()))
+	_, err := t.WriteTo(bufferResult)
+
+	return bufferResult.Bytes(), err
+}
+
+// Unmarshal decodes into v the MessagePack data, that must contain exactly one object.
+// All the integers, strings and payloads are copied, so v doesn't reference data.
+func (v *Value) Unmarshal(data []byte) error {
+	t, end, err := decode.Parse(data, 0)
+	if err != nil {
+		return err
+	}
+
+	if end != len(data) {
+		return utils.SyntaxError{Offset: end, Desc: "unexpected data after the object"}
+	}
+
+	v.t = t
+	return nil
+}
+
+// MapIter is an iterator over the key-value pairs of a MessagePack map.
+// Call Next to advance it, and Key/Value to access each pair.
+type MapIter struct {
+	m types.Map
+	i int
+}
+
+// Next advances the iterator and reports whether there is another pair.
+func (it *MapIter) Next() bool {
+	if it.i+1 >= len(it.m) {
+		return false
+	}
+	it.i++
+	return true
+}
+
+// Key returns the key of the current pair.
+func (it *MapIter) Key() Value {
+	return Value{it.m[it.i].Key}
+}
+
+// Value returns the value of the current pair.
+func (it *MapIter) Value() Value {
+	return Value{it.m[it.i].Value}
+}
@@ -0,0 +1,194 @@
+package sbor
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValue_Marshal_Unmarshal(t *testing.T) {
+	// Map with non-string and duplicated keys, in a specific order
+	v := NewMap(
+		Pair{Key: NewInt(-8), Value: NewUint(32000)},
+		Pair{Key: NewString("a"), Value: NewArray(NewNil(), NewBool(true), NewFloat(9.5))},
+		Pair{Key: NewArray(NewUint(1)), Value: NewBinary([]byte{0xCC, 0xFF})},
+		Pair{Key: NewInt(-8), Value: NewExt(0x10, []byte("test"))},
+	)
+
+	expected := []byte{0x84, 0xF8, 0xCD, 0x7D, 0x00, 0xA1, 0x61, 0x93, 0xC0, 0xC3, 0xCA, 0x41, 0x18, 0x00, 0x00, 0x91,
+		0x01, 0xC4, 0x02, 0xCC, 0xFF, 0xF8, 0xD6, 0x10, 0x74, 0x65, 0x73, 0x74}
+
+	result, err := v.Marshal()
+	if err != nil {
+		t.Errorf("Marshal Error: %v", err)
+	}
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Marshal output different than expected. Returned %v. Expected %v.", result, expected)
+	}
+
+	var decoded Value
+	if err = decoded.Unmarshal(expected); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+
+	result, err = decoded.Marshal()
+	if err != nil {
+		t.Errorf("Marshal Error: %v", err)
+	}
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Round trip output different than expected. Returned %v. Expected %v.", result, expected)
+	}
+}
+
+func TestValue_Marshal_Shortest(t *testing.T) {
+	// The values are kept, but every object is written back in its shortest format
+	data := []struct {
+		input    []byte
+		expected []byte
+		name     string
+	}{
+		{input: []byte{0xCB, 0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, expected: []byte{0xCA, 0x3F, 0xC0, 0x00, 0x00}, name: "float 64"},
+		{input: []byte{0xCD, 0x00, 0x05}, expected: []byte{0x05}, name: "uint 16"},
+		{input: []byte{0xD1, 0xFF, 0xFF}, expected: []byte{0xFF}, name: "int 16"},
+		{input: []byte{0xD9, 0x01, 0x61}, expected: []byte{0xA1, 0x61}, name: "str 8"},
+		{input: []byte{0xDC, 0x00, 0x01, 0xC0}, expected: []byte{0x91, 0xC0}, name: "array 16"},
+		{input: []byte{0xC7, 0x01, 0x10, 0x00}, expected: []byte{0xD4, 0x10, 0x00}, name: "ext 8"},
+	}
+
+	for _, test := range data {
+		var v Value
+		if err := v.Unmarshal(test.input); err != nil {
+			t.Fatalf("Unmarshal Error with %s: %v", test.name, err)
+		}
+
+		result, err := v.Marshal()
+		if err != nil {
+			t.Errorf("Marshal Error with %s: %v", test.name, err)
+		}
+		if !bytes.Equal(result, test.expected) {
+			t.Errorf("Invalid result with %s. Function returned %v. Expected %v.", test.name, result, test.expected)
+		}
+	}
+}
+
+func TestValue_Accessors(t *testing.T) {
+	data := []byte{0x84, 0xF8, 0xCD, 0x7D, 0x00, 0xA1, 0x61, 0x93, 0xC0, 0xC3, 0xCA, 0x41, 0x18, 0x00, 0x00, 0x91,
+		0x01, 0xC4, 0x02, 0xCC, 0xFF, 0xF8, 0xD6, 0x10, 0x74, 0x65, 0x73, 0x74}
+
+	var v Value
+	if err := v.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal Error: %v", err)
+	}
+
+	if v.Kind() != MapKind || v.Len() != 4 {
+		t.Errorf("Invalid map. Kind: %v, Len: %d", v.Kind(), v.Len())
+	}
+
+	// The first value of a duplicated key is returned
+	if first, ok := v.MapGet(-8); !ok || first.Kind() != UintKind || first.Uint() != 32000 || first.Int() != 32000 {
+		t.Errorf("Invalid value for key -8: %v", first)
+	}
+
+	array, ok := v.MapGet(NewString("a"))
+	if !ok || array.Kind() != ArrayKind || array.Len() != 3 {
+		t.Fatalf("Invalid value for key a: %v", array)
+	}
+	if array.Index(0).Kind() != NilKind || !array.Index(1).Bool() || array.Index(2).Float() != 9.5 {
+		t.Errorf("Invalid array elements: %v", array)
+	}
+	if array.Index(3).Kind() != InvalidKind {
+		t.Error("Out of range index must be invalid.")
+	}
+
+	if bin, ok := v.MapGet([]int{1}); !ok || !bytes.Equal(bin.Bytes(), []byte{0xCC, 0xFF}) {
+		t.Errorf("Invalid value for key [1]: %v", bin)
+	}
+
+	if _, ok = v.MapGet("missing"); ok {
+		t.Error("Missing key found.")
+	}
+
+	var keys, exts int
+	for iter := v.Iter(); iter.Next(); {
+		keys++
+		if typ, payload := iter.Value().Ext(); typ == 0x10 && string(payload) == "test" {
+			exts++
+		}
+		if iter.Key().Kind() == StringKind && iter.Key().String() != "a" {
+			t.Errorf("Invalid string key %s", iter.Key())
+		}
+	}
+	if keys != 4 || exts != 1 {
+		t.Errorf("Invalid iteration. Keys: %d, External: %d", keys, exts)
+	}
+
+	if s := NewInt(-5).String(); s != "<int Value>" {
+		t.Errorf("Invalid string of a non string value: %s", s)
+	}
+}
+
+func TestValue_Inside_Marshal(t *testing.T) {
+	exampleStruct := struct {
+		Time  Value `sbor:"t"`
+		Array []Value
+	}{
+		Time:  NewTimestamp(time.Unix(0, 0)),
+		Array: []Value{NewString("foo"), NewInt(-1)},
+	}
+
+	expected := []byte{0x82, 0xA1, 0x74, 0xD6, 0xFF, 0x00, 0x00, 0x00, 0x00, 0xA5, 0x41, 0x72, 0x72, 0x61, 0x79, 0x92,
+		0xA3, 0x66, 0x6F, 0x6F, 0xFF}
+
+	result, err := Marshal(exampleStruct)
+	if err != nil {
+		t.Errorf("Marshal Error: %v", err)
+	}
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Marshal output different than expected. Returned %v. Expected %v.", result, expected)
+	}
+}
+
+func TestValue_Inside_Unmarshal_Limits(t *testing.T) {
+	var result struct {
+		V Value
+	}
+
+	// The nesting depth continues from the struct
+	config := NewConfig().WithMaxDepth(2).WithMaxLength(2)
+	data := []struct {
+		input string
+		valid bool
+	}{
+		{input: `{"V": [1]}`, valid: true},
+		{input: `{"V": [[1]]}`, valid: false},
+		{input: `{"V": [1, 2, 3]}`, valid: false},
+	}
+
+	for _, test := range data {
+		err := config.Unmarshal(mustParseDiag(t, test.input), &result)
+		var syntax SyntaxError
+		if test.valid && err != nil || !test.valid && !errors.As(err, &syntax) {
+			t.Errorf("Invalid result for %s. Function returned %v.", test.input, err)
+		}
+	}
+}
+
+func TestValue_Error(t *testing.T) {
+	if _, err := (Value{}).Marshal(); err == nil {
+		t.Error("Marshal of the zero Value must fail.")
+	}
+
+	if _, err := NewArray(Value{}).Marshal(); err == nil {
+		t.Error("Marshal of an array with the zero Value must fail.")
+	}
+
+	var v Value
+	if err := v.Unmarshal([]byte{0xC0, 0xC0}); err == nil {
+		t.Error("Unmarshal with trailing data must fail.")
+	}
+
+	if err := v.Unmarshal([]byte{0x92, 0xC0}); err == nil {
+		t.Error("Unmarshal of truncated data must fail.")
+	}
+}